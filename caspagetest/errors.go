@@ -0,0 +1,35 @@
+package caspagetest
+
+import "fmt"
+
+// RequestError mimics an error frame returned by Cassandra. Like the driver's
+// errors it has a Code method, so core classifies it (see core.QueryError).
+type RequestError struct {
+	ErrCode int
+	Message string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("caspagetest: %s (code 0x%04x)", e.Message, e.ErrCode)
+}
+
+// Code returns the Cassandra error code.
+func (e *RequestError) Code() int {
+	return e.ErrCode
+}
+
+// Errors to use with InjectError.
+var (
+	ErrReadTimeout  = &RequestError{ErrCode: 0x1200, Message: "Operation timed out - received only 0 responses."}
+	ErrUnavailable  = &RequestError{ErrCode: 0x1000, Message: "Cannot achieve consistency level QUORUM"}
+	ErrOverloaded   = &RequestError{ErrCode: 0x1001, Message: "Coordinator is overloaded"}
+	ErrUnauthorized = &RequestError{ErrCode: 0x2100, Message: "User has no SELECT permission"}
+)
+
+func syntaxError(format string, args ...interface{}) error {
+	return &RequestError{ErrCode: 0x2000, Message: fmt.Sprintf(format, args...)}
+}
+
+func invalidError(format string, args ...interface{}) error {
+	return &RequestError{ErrCode: 0x2200, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,526 @@
+package caspagetest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/gocql/gocql"
+)
+
+// Native protocol v4 opcodes.
+const (
+	opError     byte = 0x00
+	opStartup   byte = 0x01
+	opReady     byte = 0x02
+	opOptions   byte = 0x05
+	opSupported byte = 0x06
+	opQuery     byte = 0x07
+	opResult    byte = 0x08
+	opPrepare   byte = 0x09
+	opExecute   byte = 0x0A
+	opRegister  byte = 0x0B
+)
+
+// Result kinds.
+const (
+	resultRows        = 0x0002
+	resultSetKeyspace = 0x0003
+	resultPrepared    = 0x0004
+)
+
+// Query parameter flags.
+const (
+	flagValues            = 0x01
+	flagPageSize          = 0x04
+	flagPagingState       = 0x08
+	flagSerialConsistency = 0x10
+	flagDefaultTimestamp  = 0x20
+	flagValueNames        = 0x40
+)
+
+const protocolVersion = 0x04
+
+// Frame header flags.
+const frameWarning = 0x08
+
+type frame struct {
+	version byte
+	flags   byte
+	stream  int16
+	opcode  byte
+	body    []byte
+}
+
+func readFrame(r *bufio.Reader) (frame, error) {
+	var header [9]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{
+		version: header[0],
+		flags:   header[1],
+		stream:  int16(binary.BigEndian.Uint16(header[2:4])),
+		opcode:  header[4],
+	}
+	n := binary.BigEndian.Uint32(header[5:9])
+	if n > 256<<20 {
+		return frame{}, fmt.Errorf("frame of %d bytes is too large", n)
+	}
+	f.body = make([]byte, n)
+	_, err := io.ReadFull(r, f.body)
+	return f, err
+}
+
+func (f frame) encode() []byte {
+	out := make([]byte, 9, 9+len(f.body))
+	out[0] = f.version
+	out[1] = f.flags
+	binary.BigEndian.PutUint16(out[2:4], uint16(f.stream))
+	out[4] = f.opcode
+	binary.BigEndian.PutUint32(out[5:9], uint32(len(f.body)))
+	return append(out, f.body...)
+}
+
+// writer builds a frame body.
+type writer struct {
+	b []byte
+}
+
+func (w *writer) byte(v byte) { w.b = append(w.b, v) }
+
+func (w *writer) short(v uint16) { w.b = binary.BigEndian.AppendUint16(w.b, v) }
+
+func (w *writer) int(v int32) { w.b = binary.BigEndian.AppendUint32(w.b, uint32(v)) }
+
+func (w *writer) string(s string) {
+	w.short(uint16(len(s)))
+	w.b = append(w.b, s...)
+}
+
+// bytes writes [bytes]; nil is written as null.
+func (w *writer) bytes(b []byte) {
+	if b == nil {
+		w.int(-1)
+		return
+	}
+	w.int(int32(len(b)))
+	w.b = append(w.b, b...)
+}
+
+func (w *writer) shortBytes(b []byte) {
+	w.short(uint16(len(b)))
+	w.b = append(w.b, b...)
+}
+
+func (w *writer) stringList(list []string) {
+	w.short(uint16(len(list)))
+	for _, s := range list {
+		w.string(s)
+	}
+}
+
+func (w *writer) stringMultimap(m map[string][]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w.short(uint16(len(keys)))
+	for _, k := range keys {
+		w.string(k)
+		w.short(uint16(len(m[k])))
+		for _, v := range m[k] {
+			w.string(v)
+		}
+	}
+}
+
+func (w *writer) typ(t cqlType) {
+	w.short(t.id)
+	if t.key != nil {
+		w.typ(*t.key)
+	}
+	if t.elem != nil {
+		w.typ(*t.elem)
+	}
+}
+
+// reader parses a frame body. The first error sticks and later reads return zero values.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = fmt.Errorf("truncated frame")
+		return nil
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+func (r *reader) byte() byte {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) short() uint16 {
+	if b := r.take(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) int() int32 {
+	if b := r.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *reader) string() string { return string(r.take(int(r.short()))) }
+
+func (r *reader) longString() string { return string(r.take(int(r.int()))) }
+
+func (r *reader) shortBytes() []byte { return r.take(int(r.short())) }
+
+// bytes reads [bytes]; null and unset values are returned as nil.
+func (r *reader) bytes() []byte {
+	n := r.int()
+	if n < 0 {
+		return nil
+	}
+	return r.take(int(n))
+}
+
+// queryParams are the parameters of QUERY and EXECUTE requests.
+type queryParams struct {
+	values   [][]byte
+	pageSize int
+	state    []byte
+}
+
+func (r *reader) queryParams() queryParams {
+	var p queryParams
+	r.short() // consistency
+	flags := r.byte()
+	if flags&flagValues != 0 {
+		n := int(r.short())
+		for i := 0; i < n && r.err == nil; i++ {
+			if flags&flagValueNames != 0 {
+				r.string()
+			}
+			p.values = append(p.values, r.bytes())
+		}
+	}
+	if flags&flagPageSize != 0 {
+		p.pageSize = int(r.int())
+	}
+	if flags&flagPagingState != 0 {
+		p.state = r.bytes()
+	}
+	if flags&flagSerialConsistency != 0 {
+		r.short()
+	}
+	if flags&flagDefaultTimestamp != 0 {
+		r.take(8)
+	}
+	return p
+}
+
+// cqlType is a CQL type option. Lists have an element type, maps a key type
+// and an element (value) type.
+type cqlType struct {
+	id   uint16
+	key  *cqlType
+	elem *cqlType
+}
+
+var (
+	typeBigint    = cqlType{id: 0x0002}
+	typeBlob      = cqlType{id: 0x0003}
+	typeBoolean   = cqlType{id: 0x0004}
+	typeDouble    = cqlType{id: 0x0007}
+	typeFloat     = cqlType{id: 0x0008}
+	typeInt       = cqlType{id: 0x0009}
+	typeTimestamp = cqlType{id: 0x000B}
+	typeUUID      = cqlType{id: 0x000C}
+	typeVarchar   = cqlType{id: 0x000D}
+	typeInet      = cqlType{id: 0x0010}
+	typeSmallint  = cqlType{id: 0x0013}
+	typeTinyint   = cqlType{id: 0x0014}
+)
+
+const (
+	typeList = 0x0020
+	typeMap  = 0x0021
+)
+
+func listOf(t cqlType) cqlType {
+	return cqlType{id: typeList, elem: &t}
+}
+
+func mapOf(key, elem cqlType) cqlType {
+	return cqlType{id: typeMap, key: &key, elem: &elem}
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(gocql.UUID{})
+	ipType   = reflect.TypeOf(net.IP{})
+)
+
+// typeOf maps a Go value to the CQL type it is served as.
+func typeOf(v interface{}) cqlType {
+	if v == nil {
+		return typeVarchar
+	}
+	return typeOfGo(reflect.TypeOf(v))
+}
+
+func typeOfGo(t reflect.Type) cqlType {
+	switch t {
+	case timeType:
+		return typeTimestamp
+	case uuidType:
+		return typeUUID
+	case ipType:
+		return typeInet
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return typeVarchar
+	case reflect.Bool:
+		return typeBoolean
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return typeBigint
+	case reflect.Int32, reflect.Uint16:
+		return typeInt
+	case reflect.Int16, reflect.Uint8:
+		return typeSmallint
+	case reflect.Int8:
+		return typeTinyint
+	case reflect.Float64:
+		return typeDouble
+	case reflect.Float32:
+		return typeFloat
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return typeBlob
+		}
+		return listOf(typeOfGo(t.Elem()))
+	case reflect.Map:
+		return mapOf(typeOfGo(t.Key()), typeOfGo(t.Elem()))
+	}
+	return typeVarchar
+}
+
+// encodeValue serialises v as t; nil is null.
+func encodeValue(t cqlType, v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+
+	switch t.id {
+	case typeVarchar.id:
+		if s, ok := v.(string); ok {
+			return []byte(s), nil
+		}
+		return []byte(fmt.Sprint(v)), nil
+	case typeBoolean.id:
+		if b, ok := v.(bool); ok {
+			if b {
+				return []byte{1}, nil
+			}
+			return []byte{0}, nil
+		}
+	case typeBigint.id, typeInt.id, typeSmallint.id, typeTinyint.id:
+		if n, ok := toInt(v); ok {
+			switch t.id {
+			case typeBigint.id:
+				return binary.BigEndian.AppendUint64(nil, uint64(n)), nil
+			case typeInt.id:
+				return binary.BigEndian.AppendUint32(nil, uint32(n)), nil
+			case typeSmallint.id:
+				return binary.BigEndian.AppendUint16(nil, uint16(n)), nil
+			default:
+				return []byte{byte(n)}, nil
+			}
+		}
+	case typeDouble.id, typeFloat.id:
+		var f float64
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+		default:
+			n, ok := toInt(v)
+			if !ok {
+				break
+			}
+			f = float64(n)
+		}
+		if t.id == typeFloat.id {
+			return binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(f))), nil
+		}
+		return binary.BigEndian.AppendUint64(nil, math.Float64bits(f)), nil
+	case typeTimestamp.id:
+		if ts, ok := v.(time.Time); ok {
+			return binary.BigEndian.AppendUint64(nil, uint64(ts.UnixMilli())), nil
+		}
+	case typeUUID.id:
+		switch u := v.(type) {
+		case gocql.UUID:
+			return u[:], nil
+		case string:
+			parsed, err := gocql.ParseUUID(u)
+			if err != nil {
+				return nil, err
+			}
+			return parsed[:], nil
+		}
+	case typeInet.id:
+		ip, ok := v.(net.IP)
+		if s, isString := v.(string); isString {
+			ip, ok = net.ParseIP(s), true
+		}
+		if ok && ip != nil {
+			if v4 := ip.To4(); v4 != nil {
+				return v4, nil
+			}
+			return ip, nil
+		}
+	case typeBlob.id:
+		switch b := v.(type) {
+		case []byte:
+			return b, nil
+		case string:
+			return []byte(b), nil
+		}
+	case typeList:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			var w writer
+			w.int(int32(rv.Len()))
+			for i := 0; i < rv.Len(); i++ {
+				b, err := encodeValue(*t.elem, rv.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				w.bytes(b)
+			}
+			return w.b, nil
+		}
+	case typeMap:
+		if rv.Kind() == reflect.Map {
+			// Sorted keys, so the same map always encodes the same way
+			keys := rv.MapKeys()
+			sort.Slice(keys, func(i, j int) bool {
+				return core.CompareValues(keys[i].Interface(), keys[j].Interface()) < 0
+			})
+			var w writer
+			w.int(int32(len(keys)))
+			for _, k := range keys {
+				kb, err := encodeValue(*t.key, k.Interface())
+				if err != nil {
+					return nil, err
+				}
+				vb, err := encodeValue(*t.elem, rv.MapIndex(k).Interface())
+				if err != nil {
+					return nil, err
+				}
+				w.bytes(kb)
+				w.bytes(vb)
+			}
+			return w.b, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot serve %T as CQL type 0x%04x", v, t.id)
+}
+
+// decodeValue parses a bound value of type t; null is nil.
+func decodeValue(t cqlType, b []byte) (interface{}, error) {
+	if b == nil {
+		return nil, nil
+	}
+	size := map[uint16]int{
+		typeBigint.id: 8, typeInt.id: 4, typeSmallint.id: 2, typeTinyint.id: 1,
+		typeDouble.id: 8, typeFloat.id: 4, typeTimestamp.id: 8, typeUUID.id: 16, typeBoolean.id: 1,
+	}
+	if n, ok := size[t.id]; ok && len(b) != n {
+		return nil, fmt.Errorf("value of CQL type 0x%04x has %d bytes", t.id, len(b))
+	}
+
+	switch t.id {
+	case typeBigint.id:
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case typeInt.id:
+		return int32(binary.BigEndian.Uint32(b)), nil
+	case typeSmallint.id:
+		return int16(binary.BigEndian.Uint16(b)), nil
+	case typeTinyint.id:
+		return int8(b[0]), nil
+	case typeDouble.id:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case typeFloat.id:
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), nil
+	case typeBoolean.id:
+		return b[0] != 0, nil
+	case typeTimestamp.id:
+		return time.UnixMilli(int64(binary.BigEndian.Uint64(b))).UTC(), nil
+	case typeUUID.id:
+		var u gocql.UUID
+		copy(u[:], b)
+		return u, nil
+	case typeInet.id:
+		return net.IP(append([]byte{}, b...)), nil
+	case typeBlob.id:
+		return append([]byte{}, b...), nil
+	case typeList:
+		r := reader{b: b}
+		n := int(r.int())
+		out := make([]interface{}, 0, max(n, 0))
+		for i := 0; i < n && r.err == nil; i++ {
+			v, err := decodeValue(*t.elem, r.bytes())
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, r.err
+	case typeMap:
+		if t.key.id == typeList || t.key.id == typeMap {
+			return nil, fmt.Errorf("unsupported map key of CQL type 0x%04x", t.key.id)
+		}
+		r := reader{b: b}
+		n := int(r.int())
+		out := make(map[interface{}]interface{}, max(n, 0))
+		for i := 0; i < n && r.err == nil; i++ {
+			k, err := decodeValue(*t.key, r.bytes())
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(*t.elem, r.bytes())
+			if err != nil {
+				return nil, err
+			}
+			out[k] = v
+		}
+		return out, r.err
+	}
+	return string(b), nil
+}
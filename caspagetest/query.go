@@ -0,0 +1,201 @@
+package caspagetest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// Query is an in-memory CassandraQuery.
+type Query struct {
+	session  *Session
+	stmt     string
+	values   []interface{}
+	pageSize int
+	state    []byte
+	config   core.QueryConfig
+	ctx      context.Context
+
+	diagnostics *core.PageDiagnostics
+	trace       bool
+}
+
+func (q *Query) PageSize(n int) core.CassandraQuery {
+	q.pageSize = n
+	return q
+}
+
+func (q *Query) PageState(b []byte) core.CassandraQuery {
+	q.state = b
+	return q
+}
+
+// WithContext sets the context checked before every driver page fetch.
+func (q *Query) WithContext(ctx context.Context) core.CassandraQuery {
+	q.ctx = ctx
+	return q
+}
+
+// Configure records cfg in Session.Queries; it does not change the results.
+func (q *Query) Configure(cfg core.QueryConfig) core.CassandraQuery {
+	q.config = cfg
+	return q
+}
+
+// Diagnose reports the session as the coordinator, one request per driver page
+// and the warnings injected with InjectWarning. Traced queries get sequential
+// trace ids.
+func (q *Query) Diagnose(d *core.PageDiagnostics, trace bool) core.CassandraQuery {
+	q.diagnostics, q.trace = d, trace
+	return q
+}
+
+// Iter runs the query and returns an iterator positioned at the page state.
+func (q *Query) Iter() core.CassandraIter {
+	s := q.session
+	s.mu.Lock()
+	s.queries = append(s.queries, Executed{Statement: q.stmt, Values: q.values, PageSize: q.pageSize, PageState: q.state, Config: q.config})
+	if q.diagnostics != nil {
+		q.diagnostics.Coordinator = "caspagetest"
+		q.diagnostics.Requests++
+		if q.trace {
+			s.traces++
+			q.diagnostics.TraceID = fmt.Sprintf("%032x", s.traces)
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.takeFault(q.stmt); err != nil {
+		return &Iter{err: err}
+	}
+	if err := s.wait(q.ctx); err != nil {
+		return &Iter{err: err}
+	}
+
+	rows, err := s.execute(q.stmt, q.values)
+	if err != nil {
+		return &Iter{err: err}
+	}
+
+	offset := 0
+	if len(q.state) > 0 {
+		offset, err = strconv.Atoi(string(q.state))
+		if err != nil || offset < 0 {
+			return &Iter{err: &RequestError{ErrCode: 0x000A, Message: "Invalid value for the paging state"}}
+		}
+	}
+
+	pageSize := q.pageSize
+	if pageSize <= 0 {
+		pageSize = len(rows) + 1 // paging disabled
+	}
+	warnings := s.warningsFor(q.stmt)
+	if q.diagnostics != nil {
+		for _, w := range warnings {
+			q.diagnostics.Warnings = appendNew(q.diagnostics.Warnings, w)
+		}
+	}
+	return &Iter{session: s, ctx: q.ctx, rows: rows, pos: offset, pageEnd: offset + pageSize, pageSize: pageSize, warnings: warnings, diagnostics: q.diagnostics}
+}
+
+// appendNew appends s unless list already holds it.
+func appendNew(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// Iter is an in-memory CassandraIter. Like the driver's iterator it fetches the
+// following pages transparently, honouring the latency and context of its query.
+type Iter struct {
+	session  *Session
+	ctx      context.Context
+	rows     []map[string]interface{}
+	pos      int
+	pageEnd  int
+	pageSize int
+	warnings []string
+	err      error
+
+	diagnostics *core.PageDiagnostics
+}
+
+func (i *Iter) MapScan(m map[string]interface{}) bool {
+	if i.err != nil || i.pos >= len(i.rows) {
+		return false
+	}
+	if i.pos >= i.pageEnd {
+		if err := i.session.wait(i.ctx); err != nil {
+			i.err = err
+			return false
+		}
+		i.pageEnd += i.pageSize
+		if i.diagnostics != nil {
+			i.diagnostics.Requests++
+		}
+	}
+
+	for k, v := range i.rows[i.pos] {
+		m[k] = v
+	}
+	i.pos++
+	return true
+}
+
+// PageState returns the position after the current driver page, or nil on the
+// last one. Like Cassandra, a driver page that ends exactly at the last row
+// still returns a page state, which leads to an empty page.
+func (i *Iter) PageState() []byte {
+	if i.err != nil || i.pageEnd > len(i.rows) {
+		return nil
+	}
+	return []byte(strconv.Itoa(i.pageEnd))
+}
+
+// Warnings returns the warnings injected for the query's statement.
+func (i *Iter) Warnings() []string {
+	return i.warnings
+}
+
+func (i *Iter) Close() error {
+	return i.err
+}
+
+// lookup finds a table by name, falling back to the unqualified name. The
+// caller holds s.mu.
+func (s *Session) lookup(name string) (*Table, bool) {
+	table, ok := s.tables[name]
+	if !ok {
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			table, ok = s.tables[name[i+1:]]
+		}
+	}
+	return table, ok
+}
+
+// execute evaluates a statement against the tables.
+func (s *Session) execute(stmt string, values []interface{}) ([]map[string]interface{}, error) {
+	parsed, err := parseStatement(stmt, values)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	table, ok := s.lookup(parsed.table)
+	var source []map[string]interface{}
+	if ok {
+		source = append(source, table.rows...)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, invalidError("unconfigured table %s", parsed.table)
+	}
+	return parsed.apply(source)
+}
@@ -0,0 +1,541 @@
+package caspagetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// ErrNotRecorded is returned by a Replay for a query it has no recording of.
+var ErrNotRecorded = errors.New("query not recorded")
+
+// Recording is one query execution captured by a Recorder: the request, the
+// rows it returned grouped by driver page, and how it ended. Coordinator and
+// TraceID are captured when the query was diagnosed (core.DiagnosticQuery).
+type Recording struct {
+	Statement   string            `json:"statement"`
+	Values      []core.TypedValue `json:"values,omitempty"`
+	PageSize    int               `json:"page_size"`
+	PageState   []byte            `json:"page_state,omitempty"`
+	Pages       []RecordedPage    `json:"pages,omitempty"`
+	EndState    []byte            `json:"end_state,omitempty"` // PageState when the iterator was closed
+	Error       *RecordedError    `json:"error,omitempty"`
+	Coordinator string            `json:"coordinator,omitempty"`
+	TraceID     string            `json:"trace_id,omitempty"`
+}
+
+// RecordedPage holds the rows read from one driver page, the page state
+// reported while reading them and the server warnings the page brought
+// (core.WarningIter).
+type RecordedPage struct {
+	State    []byte                       `json:"state,omitempty"`
+	Rows     []map[string]core.TypedValue `json:"rows"`
+	Warnings []string                     `json:"warnings,omitempty"`
+}
+
+// RecordedError is the error returned when the iterator was closed. Errors with
+// a Cassandra error code are replayed as a *RequestError.
+type RecordedError struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Recorder wraps a session, such as a *core.RealSession, and captures every query
+// run through it so it can be saved to a golden file and replayed offline. It is
+// safe for concurrent use.
+//
+// Only the rows the caller actually read are captured, so replaying must read
+// the same way: run the same paginator calls with the same options.
+type Recorder struct {
+	session core.CassandraSession
+
+	mu         sync.Mutex
+	recordings []*Recording
+	err        error
+}
+
+// NewRecorder wraps session.
+func NewRecorder(session core.CassandraSession) *Recorder {
+	return &Recorder{session: session}
+}
+
+// Query implements core.CassandraSession.
+func (r *Recorder) Query(stmt string, values ...interface{}) core.CassandraQuery {
+	return &recordingQuery{recorder: r, query: r.session.Query(stmt, values...), stmt: stmt, values: values}
+}
+
+// Recordings returns the executions captured so far, in order.
+func (r *Recorder) Recordings() []Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Recording, len(r.recordings))
+	for i, rec := range r.recordings {
+		out[i] = *rec
+	}
+	return out
+}
+
+// Save writes the recordings to a JSON golden file. It fails if a bound value or
+// row could not be encoded while recording.
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	b, err := json.MarshalIndent(r.recordings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode recordings: %w", err)
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// fail keeps the first encoding error for Save.
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+type recordingQuery struct {
+	recorder    *Recorder
+	query       core.CassandraQuery
+	stmt        string
+	values      []interface{}
+	pageSize    int
+	state       []byte
+	diagnostics *core.PageDiagnostics
+}
+
+func (q *recordingQuery) PageSize(n int) core.CassandraQuery {
+	q.query = q.query.PageSize(n)
+	q.pageSize = n
+	return q
+}
+
+func (q *recordingQuery) PageState(b []byte) core.CassandraQuery {
+	q.query = q.query.PageState(b)
+	q.state = b
+	return q
+}
+
+func (q *recordingQuery) WithContext(ctx context.Context) core.CassandraQuery {
+	q.query = q.query.WithContext(ctx)
+	return q
+}
+
+// Configure passes cfg on when the wrapped query supports it.
+func (q *recordingQuery) Configure(cfg core.QueryConfig) core.CassandraQuery {
+	if cq, ok := q.query.(core.ConfigurableQuery); ok {
+		q.query = cq.Configure(cfg)
+	}
+	return q
+}
+
+// Diagnose passes d on when the wrapped query supports it, and records the
+// coordinator and trace id it reports.
+func (q *recordingQuery) Diagnose(d *core.PageDiagnostics, trace bool) core.CassandraQuery {
+	if dq, ok := q.query.(core.DiagnosticQuery); ok {
+		q.query = dq.Diagnose(d, trace)
+		q.diagnostics = d
+	}
+	return q
+}
+
+func (q *recordingQuery) Iter() core.CassandraIter {
+	r := q.recorder
+	values, err := encodeValues(q.values)
+	if err != nil {
+		r.fail(fmt.Errorf("record %q: %w", q.stmt, err))
+	}
+	rec := &Recording{Statement: q.stmt, Values: values, PageSize: q.pageSize, PageState: cloneBytes(q.state)}
+
+	// Recordings are registered when the query runs, so repeated queries replay in order
+	r.mu.Lock()
+	r.recordings = append(r.recordings, rec)
+	r.mu.Unlock()
+
+	return &recordingIter{recorder: r, iter: q.query.Iter(), rec: rec, diagnostics: q.diagnostics}
+}
+
+type recordingIter struct {
+	recorder    *Recorder
+	iter        core.CassandraIter
+	rec         *Recording
+	diagnostics *core.PageDiagnostics
+	warned      int // warnings of the wrapped iterator already recorded
+}
+
+func (i *recordingIter) MapScan(m map[string]interface{}) bool {
+	if !i.iter.MapScan(m) {
+		return false
+	}
+
+	row := make(map[string]core.TypedValue, len(m))
+	for k, v := range m {
+		tv, err := core.NewTypedValue(v)
+		if err != nil {
+			i.recorder.fail(fmt.Errorf("record column %s: %w", k, err))
+		}
+		row[k] = tv
+	}
+
+	// A new page state means the driver moved to the next page
+	state := i.iter.PageState()
+	r := i.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pages := i.rec.Pages
+	if len(pages) == 0 || !bytes.Equal(pages[len(pages)-1].State, state) {
+		i.rec.Pages = append(pages, RecordedPage{State: cloneBytes(state)})
+	}
+	last := &i.rec.Pages[len(i.rec.Pages)-1]
+	last.Rows = append(last.Rows, row)
+	i.recordWarnings(state)
+	return true
+}
+
+// recordWarnings adds the warnings the wrapped iterator reported since the
+// last call to the current page. The caller holds the recorder's mutex.
+func (i *recordingIter) recordWarnings(state []byte) {
+	wi, ok := i.iter.(core.WarningIter)
+	if !ok {
+		return
+	}
+	warnings := wi.Warnings()
+	if len(warnings) <= i.warned {
+		return
+	}
+	if len(i.rec.Pages) == 0 {
+		// a query without rows still brought its warnings
+		i.rec.Pages = append(i.rec.Pages, RecordedPage{State: cloneBytes(state)})
+	}
+	last := &i.rec.Pages[len(i.rec.Pages)-1]
+	last.Warnings = append(last.Warnings, warnings[i.warned:]...)
+	i.warned = len(warnings)
+}
+
+func (i *recordingIter) PageState() []byte {
+	return i.iter.PageState()
+}
+
+// Warnings returns the wrapped iterator's warnings, when it reports them.
+func (i *recordingIter) Warnings() []string {
+	if wi, ok := i.iter.(core.WarningIter); ok {
+		return wi.Warnings()
+	}
+	return nil
+}
+
+func (i *recordingIter) Close() error {
+	state := i.iter.PageState()
+	err := i.iter.Close()
+
+	r := i.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i.recordWarnings(state)
+	i.rec.EndState = cloneBytes(state)
+	if err != nil {
+		i.rec.Error = recordError(err)
+	}
+	if d := i.diagnostics; d != nil {
+		i.rec.Coordinator, i.rec.TraceID = d.Coordinator, d.TraceID
+	}
+	return err
+}
+
+// Replay is a CassandraSession serving recordings offline. Queries are matched
+// on statement, bound values, page size and page state; repeated queries get
+// their recordings in order, the last one repeating once they run out. It is
+// safe for concurrent use.
+type Replay struct {
+	mu      sync.Mutex
+	entries map[string][]*replayEntry
+}
+
+type replayEntry struct {
+	pages       []replayPage
+	endState    []byte
+	err         error
+	coordinator string
+	traceID     string
+}
+
+type replayPage struct {
+	state    []byte
+	rows     []map[string]interface{}
+	warnings []string
+}
+
+// LoadReplay reads a golden file written by Recorder.Save.
+func LoadReplay(path string) (*Replay, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var recordings []Recording
+	if err := json.Unmarshal(b, &recordings); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return NewReplay(recordings)
+}
+
+// NewReplay creates a session serving recordings. Values are decoded with
+// core.TypedValue, so types without a dedicated encoding (collections, for
+// example) come back in their JSON form.
+func NewReplay(recordings []Recording) (*Replay, error) {
+	r := &Replay{entries: map[string][]*replayEntry{}}
+	for _, rec := range recordings {
+		entry := &replayEntry{endState: rec.EndState, err: replayError(rec.Error), coordinator: rec.Coordinator, traceID: rec.TraceID}
+		for _, page := range rec.Pages {
+			rows := make([]map[string]interface{}, len(page.Rows))
+			for j, row := range page.Rows {
+				decoded := make(map[string]interface{}, len(row))
+				for k, tv := range row {
+					v, err := tv.Decode()
+					if err != nil {
+						return nil, fmt.Errorf("decode %q column %s: %w", rec.Statement, k, err)
+					}
+					decoded[k] = v
+				}
+				rows[j] = decoded
+			}
+			entry.pages = append(entry.pages, replayPage{state: page.State, rows: rows, warnings: page.Warnings})
+		}
+
+		key, err := replayKey(rec.Statement, rec.Values, rec.PageSize, rec.PageState)
+		if err != nil {
+			return nil, err
+		}
+		r.entries[key] = append(r.entries[key], entry)
+	}
+	return r, nil
+}
+
+// Query implements core.CassandraSession.
+func (r *Replay) Query(stmt string, values ...interface{}) core.CassandraQuery {
+	return &replayQuery{replay: r, stmt: stmt, values: values, pageSize: 5000, ctx: context.Background()}
+}
+
+// take returns the next recording for key, or nil.
+func (r *Replay) take(key string) *replayEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := r.entries[key]
+	if len(entries) == 0 {
+		return nil
+	}
+	if len(entries) > 1 {
+		r.entries[key] = entries[1:]
+	}
+	return entries[0]
+}
+
+type replayQuery struct {
+	replay   *Replay
+	stmt     string
+	values   []interface{}
+	pageSize int
+	state    []byte
+	ctx      context.Context
+
+	diagnostics *core.PageDiagnostics
+	trace       bool
+}
+
+func (q *replayQuery) PageSize(n int) core.CassandraQuery {
+	q.pageSize = n
+	return q
+}
+
+func (q *replayQuery) PageState(b []byte) core.CassandraQuery {
+	q.state = b
+	return q
+}
+
+func (q *replayQuery) WithContext(ctx context.Context) core.CassandraQuery {
+	q.ctx = ctx
+	return q
+}
+
+// Diagnose reports the recorded coordinator, one request per driver page read,
+// the recorded warnings and, when tracing, the recorded trace id.
+func (q *replayQuery) Diagnose(d *core.PageDiagnostics, trace bool) core.CassandraQuery {
+	q.diagnostics, q.trace = d, trace
+	return q
+}
+
+func (q *replayQuery) Iter() core.CassandraIter {
+	if err := q.ctx.Err(); err != nil {
+		return &replayIter{err: err}
+	}
+	values, err := encodeValues(q.values)
+	if err != nil {
+		return &replayIter{err: err}
+	}
+	key, err := replayKey(q.stmt, values, q.pageSize, q.state)
+	if err != nil {
+		return &replayIter{err: err}
+	}
+
+	entry := q.replay.take(key)
+	if entry == nil {
+		return &replayIter{err: fmt.Errorf("%w: %s (page size %d, page state %q)", ErrNotRecorded, q.stmt, q.pageSize, q.state)}
+	}
+	it := &replayIter{entry: entry}
+	if len(entry.pages) == 0 {
+		it.err = entry.err
+	}
+	if q.diagnostics != nil {
+		it.collector = core.NewDiagnosticsCollector(q.diagnostics)
+		if q.trace && entry.traceID != "" {
+			if id, err := hex.DecodeString(entry.traceID); err == nil {
+				it.collector.Trace(id)
+			}
+		}
+	}
+	return it
+}
+
+// replayIter serves the recorded rows page by page, then the recorded error.
+type replayIter struct {
+	entry *replayEntry
+	page  int
+	row   int
+	err   error
+
+	collector *core.DiagnosticsCollector
+}
+
+func (i *replayIter) MapScan(m map[string]interface{}) bool {
+	if i.err != nil || i.entry == nil {
+		return false
+	}
+	pages := i.entry.pages
+	for i.page < len(pages) && i.row >= len(pages[i.page].rows) {
+		if i.page == len(pages)-1 {
+			i.err = i.entry.err
+			return false
+		}
+		i.page++
+		i.row = 0
+	}
+
+	for k, v := range pages[i.page].rows[i.row] {
+		m[k] = v
+	}
+	i.row++
+	return true
+}
+
+// PageState returns the state recorded for the current page, or the state at
+// close once the recorded rows are exhausted.
+func (i *replayIter) PageState() []byte {
+	if i.entry == nil {
+		return nil
+	}
+	pages := i.entry.pages
+	if len(pages) == 0 || (i.page == len(pages)-1 && i.row >= len(pages[i.page].rows)) {
+		return i.entry.endState
+	}
+	return pages[i.page].state
+}
+
+// Warnings returns the recorded warnings of the driver pages reached so far.
+func (i *replayIter) Warnings() []string {
+	if i.entry == nil {
+		return nil
+	}
+	var out []string
+	for p := 0; p <= i.page && p < len(i.entry.pages); p++ {
+		out = append(out, i.entry.pages[p].warnings...)
+	}
+	return out
+}
+
+func (i *replayIter) Close() error {
+	if i.collector != nil && i.entry != nil {
+		for p := 0; p <= i.page && (p < len(i.entry.pages) || p == 0); p++ {
+			i.collector.Request(i.entry.coordinator)
+		}
+		i.collector.Warn(i.Warnings())
+		i.collector.Flush()
+	}
+	return i.err
+}
+
+// replayKey identifies a query execution.
+func replayKey(stmt string, values []core.TypedValue, pageSize int, state []byte) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("encode values of %q: %w", stmt, err)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%x", stmt, b, pageSize, state), nil
+}
+
+func encodeValues(values []interface{}) ([]core.TypedValue, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	out := make([]core.TypedValue, len(values))
+	for i, v := range values {
+		tv, err := core.NewTypedValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("encode value %d: %w", i, err)
+		}
+		out[i] = tv
+	}
+	return out, nil
+}
+
+// recordError keeps the error code and message; context errors are kept by
+// message so they still match errors.Is on replay.
+func recordError(err error) *RecordedError {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &RecordedError{Message: context.Canceled.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &RecordedError{Message: context.DeadlineExceeded.Error()}
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return &RecordedError{Code: reqErr.ErrCode, Message: reqErr.Message}
+	}
+	rec := &RecordedError{Message: err.Error()}
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		rec.Code = coded.Code()
+	}
+	return rec
+}
+
+func replayError(rec *RecordedError) error {
+	switch {
+	case rec == nil:
+		return nil
+	case rec.Code != 0:
+		return &RequestError{ErrCode: rec.Code, Message: rec.Message}
+	case rec.Message == context.Canceled.Error():
+		return context.Canceled
+	case rec.Message == context.DeadlineExceeded.Error():
+		return context.DeadlineExceeded
+	}
+	return errors.New(rec.Message)
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,150 @@
+package caspagetest_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// walk reads every page and returns the rows and the tokens.
+func walk(t *testing.T, s core.CassandraSession, opts core.Options) ([]int64, []string) {
+	t.Helper()
+	p := core.NewPaginator(s, "SELECT * FROM events", opts)
+	it := p.Pages("")
+	defer it.Close()
+	var rows []int64
+	var tokens []string
+	for it.Next() {
+		rows = append(rows, ids(it.Rows())...)
+		tokens = append(tokens, it.Token())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rows, tokens
+}
+
+func TestRecorder_ReplaysGoldenFile(t *testing.T) {
+	live := caspagetest.NewSession()
+	rows := events(12)
+	for i, row := range rows {
+		row["at"] = time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC)
+	}
+	live.AddTable("events", rows)
+	opts := core.Options{PageSize: 5, Filters: map[string]interface{}{"device": "d1"}}
+
+	rec := caspagetest.NewRecorder(live)
+	wantRows, wantTokens := walk(t, rec, opts)
+	if len(rec.Recordings()) != 3 {
+		t.Fatalf("expected 3 recordings, got %d", len(rec.Recordings()))
+	}
+
+	path := filepath.Join(t.TempDir(), "events.golden.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	replay, err := caspagetest.LoadReplay(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	gotRows, gotTokens := walk(t, replay, opts)
+	if !reflect.DeepEqual(gotRows, wantRows) || !reflect.DeepEqual(gotTokens, wantTokens) {
+		t.Errorf("replay differs:\n got %v %v\nwant %v %v", gotRows, gotTokens, wantRows, wantTokens)
+	}
+
+	// Values keep their Go types
+	p := core.NewPaginator(replay, "SELECT * FROM events", opts)
+	page, _, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if at, ok := page[1]["at"].(time.Time); !ok || !at.Equal(time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)) {
+		t.Errorf("expected a time.Time, got %#v", page[1]["at"])
+	}
+}
+
+func TestReplay_Errors(t *testing.T) {
+	live := caspagetest.NewSession()
+	live.AddTable("events", events(10))
+	live.InjectError("FROM events", caspagetest.ErrReadTimeout, 1)
+
+	rec := caspagetest.NewRecorder(live)
+	p := core.NewPaginator(rec, "SELECT * FROM events", core.Options{PageSize: 5})
+	if _, _, err := p.Next(); err == nil {
+		t.Fatal("expected the injected error while recording")
+	}
+	if _, _, err := p.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	replay, err := caspagetest.NewReplay(rec.Recordings())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p = core.NewPaginator(replay, "SELECT * FROM events", core.Options{PageSize: 5})
+
+	// The recorded failure replays first, with its error code
+	_, _, err = p.Next()
+	var qe *core.QueryError
+	if !errors.As(err, &qe) || qe.Kind != core.KindTimeout {
+		t.Fatalf("expected a timeout QueryError, got %v", err)
+	}
+	if rows, _, err := p.Next(); err != nil || len(rows) != 5 {
+		t.Fatalf("expected the recorded page, got %d rows, err %v", len(rows), err)
+	}
+
+	// Queries that were never recorded fail
+	p.PageSize = 3
+	if _, _, err := p.Next(); !errors.Is(err, caspagetest.ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded, got %v", err)
+	}
+}
+
+func TestRecorder_KeepsDiagnosticsAndWarnings(t *testing.T) {
+	live := caspagetest.NewSession()
+	live.AddTable("events", events(12))
+	live.InjectWarning("FROM events", "Read 5 live rows and 3000 tombstone cells")
+	opts := core.Options{
+		PageSize:    5,
+		Adaptive:    &core.AdaptiveFetch{MinFetchSize: 2, MaxFetchSize: 2},
+		Diagnostics: &core.Diagnostics{Trace: true},
+	}
+
+	fetch := func(s core.CassandraSession) core.PageInfo {
+		t.Helper()
+		_, info, err := core.NewPaginator(s, "SELECT * FROM events", opts).NextWithTokenInfo(context.Background(), "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return info
+	}
+
+	rec := caspagetest.NewRecorder(live)
+	want := fetch(rec)
+	if len(want.Warnings) != 1 || want.Diagnostics == nil || want.Diagnostics.Coordinator != "caspagetest" || want.Diagnostics.TraceID == "" {
+		t.Fatalf("expected the live diagnostics through the recorder, got %+v %+v", want, want.Diagnostics)
+	}
+	if pages := rec.Recordings()[0].Pages; len(pages) != 3 || len(pages[0].Warnings) != 1 {
+		t.Errorf("expected the warning recorded with the first driver page, got %+v", pages)
+	}
+
+	replay, err := caspagetest.NewReplay(rec.Recordings())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := fetch(replay)
+	if !reflect.DeepEqual(got.Warnings, want.Warnings) {
+		t.Errorf("expected warnings %v, got %v", want.Warnings, got.Warnings)
+	}
+	w, g := want.Diagnostics, got.Diagnostics
+	if g == nil || g.Coordinator != w.Coordinator || g.Requests != w.Requests || g.TraceID != w.TraceID || !reflect.DeepEqual(g.Warnings, w.Warnings) {
+		t.Errorf("expected diagnostics %+v, got %+v", w, g)
+	}
+}
@@ -0,0 +1,467 @@
+package caspagetest
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gocql/gocql"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// Server serves a Session over the Cassandra native protocol (v4), so real
+// drivers can be tested end to end without a cluster. It answers the
+// system.local and system.peers queries drivers make when connecting, and runs
+// every other statement against the session with its paging, injected errors
+// and latency. Column types are inferred from the Go values in each table.
+//
+//	srv, err := caspagetest.NewServer(s)
+//	defer srv.Close()
+//	cluster := gocql.NewCluster(srv.Addr())
+//	cluster.ProtoVersion = 4
+type Server struct {
+	session *Session
+	system  *Session
+	ln      net.Listener
+
+	mu        sync.Mutex
+	prepared  map[string]string   // statement by prepared id
+	supported map[string][]string // options answered to OPTIONS
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer starts a server for session on a free local port.
+func NewServer(session *Session) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &Server{
+		session:  session,
+		system:   NewSession(),
+		ln:       ln,
+		prepared: map[string]string{},
+		conns:    map[net.Conn]struct{}{},
+		supported: map[string][]string{
+			"CQL_VERSION": {"3.4.5"},
+			"COMPRESSION": {},
+		},
+	}
+	ip := net.ParseIP("127.0.0.1")
+	srv.system.AddTable("system.local", []map[string]interface{}{{
+		"key":               "local",
+		"cluster_name":      "caspagetest",
+		"data_center":       "datacenter1",
+		"rack":              "rack1",
+		"host_id":           gocql.MustRandomUUID(),
+		"schema_version":    gocql.MustRandomUUID(),
+		"release_version":   "4.0.0",
+		"partitioner":       "org.apache.cassandra.dht.Murmur3Partitioner",
+		"broadcast_address": ip,
+		"listen_address":    ip,
+		"rpc_address":       ip,
+		"tokens":            []string{"0"},
+	}})
+	srv.system.AddTable("system.peers", nil)
+
+	srv.wg.Add(1)
+	go srv.accept()
+	return srv, nil
+}
+
+// Addr returns the host:port the server listens on.
+func (srv *Server) Addr() string {
+	return srv.ln.Addr().String()
+}
+
+// SetSupported adds an option to the SUPPORTED response of the handshake, for
+// example Scylla's SCYLLA_NR_SHARDS and SCYLLA_SHARDING_IGNORE_MSB.
+func (srv *Server) SetSupported(option string, values ...string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.supported[option] = values
+}
+
+// Close stops the server and drops its connections.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	srv.closed = true
+	for conn := range srv.conns {
+		conn.Close()
+	}
+	srv.mu.Unlock()
+
+	err := srv.ln.Close()
+	srv.wg.Wait()
+	return err
+}
+
+func (srv *Server) accept() {
+	defer srv.wg.Done()
+	for {
+		conn, err := srv.ln.Accept()
+		if err != nil {
+			return
+		}
+		srv.mu.Lock()
+		if srv.closed {
+			srv.mu.Unlock()
+			conn.Close()
+			return
+		}
+		srv.conns[conn] = struct{}{}
+		srv.mu.Unlock()
+
+		srv.wg.Add(1)
+		go srv.serve(conn)
+	}
+}
+
+// serve reads requests from one connection. Requests run concurrently, like
+// streams on a real node, and responses are written as they complete.
+func (srv *Server) serve(conn net.Conn) {
+	var requests sync.WaitGroup
+	defer func() {
+		conn.Close()
+		requests.Wait()
+		srv.mu.Lock()
+		delete(srv.conns, conn)
+		srv.mu.Unlock()
+		srv.wg.Done()
+	}()
+
+	var writeMu sync.Mutex
+	r := bufio.NewReader(conn)
+	for {
+		req, err := readFrame(r)
+		if err != nil {
+			return
+		}
+
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			var warnings []string
+			opcode, body := srv.handle(req, &warnings)
+			resp := frame{version: 0x80 | protocolVersion, stream: req.stream, opcode: opcode, body: body}
+			if len(warnings) > 0 {
+				var w writer
+				w.stringList(warnings)
+				resp.flags |= frameWarning
+				resp.body = append(w.b, body...)
+			}
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			conn.Write(resp.encode())
+		}()
+	}
+}
+
+// handle answers one request, adding the server warnings of queries to warnings.
+func (srv *Server) handle(req frame, warnings *[]string) (byte, []byte) {
+	if req.version&0x7F != protocolVersion {
+		return errorBody(&RequestError{ErrCode: 0x000A, Message: fmt.Sprintf("unsupported protocol version %d", req.version&0x7F)})
+	}
+
+	r := &reader{b: req.body}
+	switch req.opcode {
+	case opOptions:
+		var w writer
+		srv.mu.Lock()
+		w.stringMultimap(srv.supported)
+		srv.mu.Unlock()
+		return opSupported, w.b
+	case opStartup, opRegister:
+		return opReady, nil
+	case opQuery:
+		stmt := r.longString()
+		params := r.queryParams()
+		if r.err != nil {
+			return errorBody(&RequestError{ErrCode: 0x000A, Message: r.err.Error()})
+		}
+		return srv.query(stmt, nil, params, warnings)
+	case opPrepare:
+		stmt := r.longString()
+		if r.err != nil {
+			return errorBody(&RequestError{ErrCode: 0x000A, Message: r.err.Error()})
+		}
+		return srv.prepare(stmt)
+	case opExecute:
+		id := r.shortBytes()
+		params := r.queryParams()
+		if r.err != nil {
+			return errorBody(&RequestError{ErrCode: 0x000A, Message: r.err.Error()})
+		}
+		srv.mu.Lock()
+		stmt, ok := srv.prepared[string(id)]
+		srv.mu.Unlock()
+		if !ok {
+			var w writer
+			w.int(0x2500)
+			w.string("prepared statement not found")
+			w.shortBytes(id)
+			return opError, w.b
+		}
+		return srv.execute(stmt, params, warnings)
+	}
+	return errorBody(&RequestError{ErrCode: 0x000A, Message: fmt.Sprintf("unsupported opcode 0x%02x", req.opcode)})
+}
+
+var (
+	usePattern    = regexp.MustCompile(`(?is)^\s*USE\s+"?(\w+)"?\s*;?\s*$`)
+	systemPattern = regexp.MustCompile(`(?is)\bFROM\s+system(_schema)?\.`)
+)
+
+// sessionFor routes system tables to the server's own session.
+func (srv *Server) sessionFor(stmt string) *Session {
+	if systemPattern.MatchString(stmt) {
+		return srv.system
+	}
+	return srv.session
+}
+
+func (srv *Server) prepare(stmt string) (byte, []byte) {
+	parsed, markers, err := prepareStatement(stmt)
+	if err != nil {
+		return errorBody(err)
+	}
+	session := srv.sessionFor(stmt)
+	types := session.columnTypes(parsed.table)
+
+	sum := sha256.Sum256([]byte(stmt))
+	id := sum[:16]
+	srv.mu.Lock()
+	srv.prepared[string(id)] = stmt
+	srv.mu.Unlock()
+
+	var w writer
+	w.int(resultPrepared)
+	w.shortBytes(id)
+
+	// Bind markers
+	w.int(0x0001) // global table spec
+	w.int(int32(len(markers)))
+	w.int(0) // no partition key indexes
+	writeTableSpec(&w, parsed.table)
+	for _, m := range markers {
+		name := m.column
+		switch {
+		case m.token:
+			name = "partition key token"
+		case name == "":
+			name = "[limit]"
+		}
+		w.string(name)
+		w.typ(markerType(types, m))
+	}
+
+	// Result columns
+	columns, columnTypes := parsed.resultColumns(types)
+	w.int(0x0001)
+	w.int(int32(len(columns)))
+	writeTableSpec(&w, parsed.table)
+	for i, c := range columns {
+		w.string(c)
+		w.typ(columnTypes[i])
+	}
+	return opResult, w.b
+}
+
+// execute binds the values of a prepared statement and runs it.
+func (srv *Server) execute(stmt string, params queryParams, warnings *[]string) (byte, []byte) {
+	parsed, markers, err := prepareStatement(stmt)
+	if err != nil {
+		return errorBody(err)
+	}
+	if len(params.values) != len(markers) {
+		return errorBody(invalidError("statement has %d bind markers, got %d values", len(markers), len(params.values)))
+	}
+
+	types := srv.sessionFor(stmt).columnTypes(parsed.table)
+	values := make([]interface{}, len(markers))
+	for i, m := range markers {
+		if values[i], err = decodeValue(markerType(types, m), params.values[i]); err != nil {
+			return errorBody(invalidError("bind marker %d: %v", i, err))
+		}
+	}
+	return srv.query(stmt, values, params, warnings)
+}
+
+// query runs a statement and encodes one page of rows.
+func (srv *Server) query(stmt string, values []interface{}, params queryParams, warnings *[]string) (byte, []byte) {
+	if m := usePattern.FindStringSubmatch(stmt); m != nil {
+		var w writer
+		w.int(resultSetKeyspace)
+		w.string(m[1])
+		return opResult, w.b
+	}
+
+	parsed, err := parseStatement(stmt, values)
+	if err != nil {
+		return errorBody(err)
+	}
+	session := srv.sessionFor(stmt)
+	columns, types := parsed.resultColumns(session.columnTypes(parsed.table))
+
+	// The page size is the driver's; without one the whole result is returned
+	var diagnostics core.PageDiagnostics
+	q := session.Query(stmt, values...).PageSize(params.pageSize).PageState(params.state)
+	iter := q.(*Query).Diagnose(&diagnostics, false).Iter()
+	var rows []map[string]interface{}
+	row := map[string]interface{}{}
+	for (params.pageSize <= 0 || len(rows) < params.pageSize) && iter.MapScan(row) {
+		rows = append(rows, row)
+		row = map[string]interface{}{}
+	}
+	state := iter.PageState()
+	if err := iter.Close(); err != nil {
+		return errorBody(err)
+	}
+	*warnings = diagnostics.Warnings
+
+	var w writer
+	w.int(resultRows)
+	flags := int32(0x0001)
+	if len(state) > 0 {
+		flags |= 0x0002
+	}
+	w.int(flags)
+	w.int(int32(len(columns)))
+	if len(state) > 0 {
+		w.bytes(state)
+	}
+	writeTableSpec(&w, parsed.table)
+	for i, c := range columns {
+		w.string(c)
+		w.typ(types[i])
+	}
+
+	w.int(int32(len(rows)))
+	for _, row := range rows {
+		for i, c := range columns {
+			b, err := encodeValue(types[i], row[c])
+			if err != nil {
+				return errorBody(&RequestError{ErrCode: 0x0000, Message: fmt.Sprintf("column %s: %v", c, err)})
+			}
+			w.bytes(b)
+		}
+	}
+	return opResult, w.b
+}
+
+// resultColumns returns the selected columns and their types.
+func (s *statement) resultColumns(types map[string]cqlType) ([]string, []cqlType) {
+	if s.count {
+		return []string{"count"}, []cqlType{typeBigint}
+	}
+	columns := s.columns
+	if columns == nil {
+		for c := range types {
+			columns = append(columns, c)
+		}
+		sort.Strings(columns)
+	}
+	out := make([]cqlType, len(columns))
+	for i, c := range columns {
+		out[i] = columnType(types, c)
+	}
+	return columns, out
+}
+
+// columnTypes infers the CQL type of every column of a table from its first
+// non-null value.
+func (s *Session) columnTypes(name string) map[string]cqlType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	types := map[string]cqlType{}
+	table, ok := s.lookup(name)
+	if !ok {
+		return types
+	}
+	typed := map[string]bool{}
+	for _, row := range table.rows {
+		for c, v := range row {
+			if typed[c] {
+				continue
+			}
+			types[c] = typeOf(v) // varchar until a value is seen
+			typed[c] = v != nil
+		}
+	}
+	return types
+}
+
+// columnType returns the type of a column, varchar when unknown.
+func columnType(types map[string]cqlType, column string) cqlType {
+	if t, ok := types[column]; ok {
+		return t
+	}
+	return typeVarchar
+}
+
+// markerType returns the type a bind marker takes: bigint for a token, int for
+// LIMIT, a list of the column type for "IN ?".
+func markerType(types map[string]cqlType, m marker) cqlType {
+	switch {
+	case m.token:
+		return typeBigint
+	case m.column == "":
+		return typeInt
+	case m.list:
+		return listOf(columnType(types, m.column))
+	}
+	return columnType(types, m.column)
+}
+
+func writeTableSpec(w *writer, table string) {
+	keyspace := "caspagetest"
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		keyspace, table = table[:i], table[i+1:]
+	}
+	w.string(keyspace)
+	w.string(table)
+}
+
+// errorBody encodes err as an ERROR frame, with the extra fields its code requires.
+func errorBody(err error) (byte, []byte) {
+	code, message := 0x0000, err.Error()
+	var reqErr *RequestError
+	switch {
+	case errors.As(err, &reqErr):
+		code, message = reqErr.ErrCode, reqErr.Message
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		code = 0x1200 // the node gave up waiting, as a read timeout
+	}
+
+	var w writer
+	w.int(int32(code))
+	w.string(message)
+	switch code {
+	case 0x1000: // unavailable: consistency, required, alive
+		w.short(uint16(gocql.Quorum))
+		w.int(1)
+		w.int(0)
+	case 0x1100: // write timeout: consistency, received, block for, write type
+		w.short(uint16(gocql.Quorum))
+		w.int(0)
+		w.int(1)
+		w.string("SIMPLE")
+	case 0x1200: // read timeout: consistency, received, block for, data present
+		w.short(uint16(gocql.Quorum))
+		w.int(0)
+		w.int(1)
+		w.byte(0)
+	}
+	return opError, w.b
+}
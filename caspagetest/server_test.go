@@ -0,0 +1,53 @@
+package caspagetest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/gocql/gocql"
+)
+
+func TestServer_ServesDrivers(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("ks.events", events(10))
+	srv, err := caspagetest.NewServer(s)
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	defer srv.Close()
+
+	cluster := gocql.NewCluster(srv.Addr())
+	cluster.ProtoVersion = 4
+	cluster.Timeout = 5 * time.Second
+	session, err := cluster.CreateSession()
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	// Prepared statement with list and LIMIT markers, paged by the driver
+	iter := session.Query("SELECT ts, region FROM ks.events WHERE region IN ? AND ts > ? LIMIT ?",
+		[]string{"US", "EU"}, 1, 4).PageSize(3).Iter()
+	var got []int64
+	var ts int64
+	var region string
+	for iter.Scan(&ts, &region) {
+		got = append(got, ts)
+	}
+	if err := iter.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int64{2, 3, 5, 6}; len(got) != len(want) || got[0] != 2 || got[3] != 6 {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Injected errors reach the driver with their code
+	s.InjectError("FROM ks.events", caspagetest.ErrOverloaded, -1)
+	err = session.Query("SELECT * FROM ks.events").Exec()
+	var reqErr gocql.RequestError
+	if !errors.As(err, &reqErr) || reqErr.Code() != gocql.ErrCodeOverloaded {
+		t.Errorf("expected an overloaded error, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+// Package caspagetest provides an in-memory core.CassandraSession for testing
+// paging code without a cluster.
+//
+// Tables are plain slices of rows. Queries support the subset of CQL the
+// paginators generate: column lists, COUNT(*), WHERE relations joined by AND
+// (=, !=, <, <=, >, >=, IN, tuple comparisons and token() ranges), ORDER BY,
+// LIMIT and ALLOW FILTERING. Page size and page state behave like the real driver: the
+// iterator fetches following pages transparently and PageState returns the
+// position after the current driver page.
+//
+// Recorder and Replay capture queries against a real cluster to a golden file
+// and serve them back offline.
+//
+//	s := caspagetest.NewSession()
+//	s.AddTable("users", rows)
+//	s.InjectError("FROM users", caspagetest.ErrReadTimeout, 1)
+//	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{PageSize: 10})
+package caspagetest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// Session is an in-memory CassandraSession. It is safe for concurrent use.
+type Session struct {
+	mu       sync.Mutex
+	tables   map[string]*Table
+	faults   []*fault
+	warnings []warning
+	latency  time.Duration
+	queries  []Executed
+	traces   int
+}
+
+// Executed describes a query run against the session.
+type Executed struct {
+	Statement string
+	Values    []interface{}
+	PageSize  int
+	PageState []byte
+	Config    core.QueryConfig // settings applied through core.ConfigurableQuery
+}
+
+// fault makes matching queries fail.
+type fault struct {
+	match string
+	err   error
+	times int // remaining failures, negative for unlimited
+}
+
+// warning is a server warning reported for matching queries.
+type warning struct {
+	match string
+	text  string
+}
+
+// NewSession creates an empty session.
+func NewSession() *Session {
+	return &Session{tables: map[string]*Table{}}
+}
+
+// AddTable creates or replaces a table. Rows are returned in insertion order,
+// which stands in for Cassandra's token and clustering order. A table added
+// without a keyspace also answers queries that qualify it ("ks.users").
+func (s *Session) AddTable(name string, rows []map[string]interface{}) *Table {
+	t := &Table{session: s, name: name}
+	t.Insert(rows...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[strings.ToLower(name)] = t
+	return t
+}
+
+// Table returns the named table, or nil.
+func (s *Session) Table(name string) *Table {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables[strings.ToLower(name)]
+}
+
+// InjectError makes the next times queries whose statement contains match fail
+// with err (times < 0: every query). An empty match applies to every query.
+func (s *Session) InjectError(match string, err error, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{match: match, err: err, times: times})
+}
+
+// InjectWarning makes every query whose statement contains match report the
+// server warning text, as Cassandra does when a read crosses the tombstone
+// warning threshold. Warnings reach core.PageDiagnostics and the drivers
+// connected to a Server.
+func (s *Session) InjectWarning(match, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warnings = append(s.warnings, warning{match: match, text: text})
+}
+
+// ClearErrors removes all injected errors.
+func (s *Session) ClearErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetLatency delays every driver page fetch by d, or until the query's context is done.
+func (s *Session) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Queries returns the queries executed so far, in order.
+func (s *Session) Queries() []Executed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Executed(nil), s.queries...)
+}
+
+// Query implements core.CassandraSession.
+func (s *Session) Query(stmt string, values ...interface{}) core.CassandraQuery {
+	return &Query{session: s, stmt: stmt, values: values, pageSize: 5000, ctx: context.Background()}
+}
+
+// takeFault returns the error injected for stmt, if any, consuming one failure.
+func (s *Session) takeFault(stmt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.faults {
+		if f.times == 0 || !strings.Contains(stmt, f.match) {
+			continue
+		}
+		if f.times > 0 {
+			f.times--
+		}
+		return f.err
+	}
+	return nil
+}
+
+// warningsFor returns the warnings injected for stmt.
+func (s *Session) warningsFor(stmt string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for _, w := range s.warnings {
+		if strings.Contains(stmt, w.match) {
+			out = append(out, w.text)
+		}
+	}
+	return out
+}
+
+// wait sleeps for the configured latency unless ctx is done first.
+func (s *Session) wait(ctx context.Context) error {
+	s.mu.Lock()
+	latency := s.latency
+	s.mu.Unlock()
+
+	if latency <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Table is an in-memory table.
+type Table struct {
+	session *Session
+	name    string
+	rows    []map[string]interface{}
+}
+
+// Insert appends rows. Rows are copied, so later changes to the maps do not leak in.
+func (t *Table) Insert(rows ...map[string]interface{}) {
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+	for _, row := range rows {
+		t.rows = append(t.rows, copyRow(row))
+	}
+}
+
+// Rows returns a copy of the table's rows.
+func (t *Table) Rows() []map[string]interface{} {
+	t.session.mu.Lock()
+	defer t.session.mu.Unlock()
+	rows := make([]map[string]interface{}, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = copyRow(row)
+	}
+	return rows
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		c[k] = v
+	}
+	return c
+}
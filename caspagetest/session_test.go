@@ -0,0 +1,198 @@
+package caspagetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// events builds rows for one partition ordered by ts.
+func events(n int) []map[string]interface{} {
+	regions := []string{"US", "CA", "EU"}
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{
+			"device": "d1",
+			"ts":     int64(i),
+			"region": regions[i%len(regions)],
+		}
+	}
+	return rows
+}
+
+func ids(rows []map[string]interface{}) []int64 {
+	out := make([]int64, len(rows))
+	for i, row := range rows {
+		out[i] = row["ts"].(int64)
+	}
+	return out
+}
+
+func TestSession_PagesWithPageState(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("events", events(23))
+	p := core.NewPaginator(s, "SELECT * FROM events", core.Options{PageSize: 5})
+
+	it := p.Pages("")
+	defer it.Close()
+	var got []int64
+	pages := 0
+	for it.Next() {
+		got = append(got, ids(it.Rows())...)
+		pages++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages != 5 || len(got) != 23 || got[22] != 22 {
+		t.Errorf("got %d pages with %v", pages, got)
+	}
+
+	queries := s.Queries()
+	if len(queries) != 5 || queries[0].PageSize != 5 || string(queries[1].PageState) != "5" {
+		t.Errorf("unexpected queries: %+v", queries)
+	}
+}
+
+func TestSession_FiltersAndProjection(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("ks.events", events(30))
+	p := core.NewPaginator(s, "SELECT * FROM ks.events", core.Options{
+		PageSize: 100,
+		Columns:  []string{"ts", "region"},
+		Filters: map[string]interface{}{
+			"device":    "d1",
+			"region IN": []string{"US", "CA"},
+			"ts >=":     10,
+			"ts <":      20,
+		},
+	})
+
+	rows, _, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []int64{10, 12, 13, 15, 16, 18, 19}
+	if got := ids(rows); len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i, row := range rows {
+		if row["ts"] != want[i] || row["region"] == "EU" || len(row) != 2 {
+			t.Errorf("unexpected row %d: %v", i, row)
+		}
+	}
+}
+
+func TestSession_KeysetAndCount(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("events", events(12))
+	p := core.NewPaginator(s, "SELECT * FROM events", core.Options{
+		PageSize: 5,
+		Keyset:   true,
+		Keys:     &core.TableKeys{PartitionKeys: []string{"device"}, ClusteringKeys: []string{"ts"}},
+		Filters:  map[string]interface{}{"device": "d1"},
+	})
+
+	rows, token, err := p.Last()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ids(rows); len(got) != 5 || got[0] != 7 || got[4] != 11 {
+		t.Errorf("expected the last page 7..11, got %v", got)
+	}
+	rows, _, err = p.Previous(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ids(rows); len(got) != 5 || got[0] != 2 {
+		t.Errorf("expected the page 2..6, got %v", got)
+	}
+
+	count, err := p.Count(core.CountOptions{Mode: core.CountExact})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count.Count != 12 {
+		t.Errorf("expected 12 rows, got %d", count.Count)
+	}
+}
+
+func TestSession_InjectedErrors(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("events", events(10))
+	s.InjectError("FROM events", caspagetest.ErrReadTimeout, 2)
+
+	p := core.NewPaginator(s, "SELECT * FROM events", core.Options{PageSize: 5})
+	_, _, err := p.Next()
+	var qe *core.QueryError
+	if !errors.As(err, &qe) || qe.Kind != core.KindTimeout {
+		t.Fatalf("expected a timeout QueryError, got %v", err)
+	}
+
+	// The second failure is absorbed by a retry
+	p.Opts.Retry = &core.RetryPolicy{BaseDelay: time.Millisecond}
+	rows, _, err := p.Next()
+	if err != nil || len(rows) != 5 {
+		t.Fatalf("expected a successful retry, got %d rows, err %v", len(rows), err)
+	}
+}
+
+func TestSession_LatencyHonoursContext(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("events", events(10))
+	s.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	p := core.NewPaginator(s, "SELECT * FROM events", core.Options{PageSize: 5, Context: ctx})
+
+	start := time.Now()
+	_, _, err := p.Next()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("latency ignored the context: %v", elapsed)
+	}
+}
+
+func TestSession_Statements(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("events", events(10))
+
+	scan := func(stmt string, values ...interface{}) ([]map[string]interface{}, error) {
+		iter := s.Query(stmt, values...).Iter()
+		var rows []map[string]interface{}
+		row := map[string]interface{}{}
+		for iter.MapScan(row) {
+			rows = append(rows, row)
+			row = map[string]interface{}{}
+		}
+		return rows, iter.Close()
+	}
+
+	rows, err := scan("SELECT ts FROM events WHERE region = 'EU' ORDER BY ts DESC LIMIT 2")
+	if err != nil || len(rows) != 2 || rows[0]["ts"] != int64(8) || rows[1]["ts"] != int64(5) {
+		t.Errorf("literal filter: got %v, err %v", rows, err)
+	}
+
+	rows, err = scan("SELECT * FROM events WHERE (device, ts) > (?, ?) AND ts IN ?", "d1", 6, []int{1, 7, 9})
+	if err != nil || len(rows) != 2 || rows[0]["ts"] != int64(7) {
+		t.Errorf("tuple relation: got %v, err %v", rows, err)
+	}
+
+	var reqErr *caspagetest.RequestError
+	if _, err := scan("SELECT * FROM missing"); !errors.As(err, &reqErr) || reqErr.Code() != 0x2200 {
+		t.Errorf("expected an invalid query error for a missing table, got %v", err)
+	}
+	if _, err := scan("SELECT * FROM events WHERE token(device) IN ?", []int64{1}); !errors.As(err, &reqErr) || reqErr.Code() != 0x2200 {
+		t.Errorf("expected an invalid query error for token() IN, got %v", err)
+	}
+	if _, err := scan("SELECT * FROM events WHERE ts = ?"); err == nil {
+		t.Error("expected an error for a missing bind value")
+	}
+}
@@ -0,0 +1,418 @@
+package caspagetest
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// statement is a parsed SELECT.
+type statement struct {
+	table     string
+	columns   []string // nil selects every column
+	count     bool
+	relations []relation
+	orderBy   []ordering
+	limit     int
+}
+
+// relation is one WHERE condition. Tuple relations have several columns and
+// values; token relations compare the token of their columns, the partition key.
+type relation struct {
+	columns []string
+	op      string
+	values  []interface{} // one value, the IN list, or the tuple
+	token   bool
+}
+
+type ordering struct {
+	column     string
+	descending bool
+}
+
+var selectPattern = regexp.MustCompile(`(?is)^\s*SELECT\s+(.+?)\s+FROM\s+([\w."]+)` +
+	`(?:\s+WHERE\s+(.+?))?(?:\s+ORDER\s+BY\s+(.+?))?(?:\s+LIMIT\s+(\S+))?(?:\s+ALLOW\s+FILTERING)?\s*;?\s*$`)
+
+var relationPattern = regexp.MustCompile(`(?is)^(token\s*\(.+?\)|\(.+?\)|[\w"]+)\s*(<=|>=|!=|=|<|>|\bIN\b|\bCONTAINS\b)\s*(.+)$`)
+
+// parseStatement parses a SELECT and binds the values to its markers.
+func parseStatement(stmt string, values []interface{}) (*statement, error) {
+	binder := &binder{values: values}
+	s, err := parse(stmt, binder)
+	if err != nil {
+		return nil, err
+	}
+	if binder.next != len(values) {
+		return nil, invalidError("statement has %d bind markers, got %d values", binder.next, len(values))
+	}
+	return s, nil
+}
+
+// prepareStatement parses a SELECT without values and returns the column each
+// bind marker is compared with (see binder.markers).
+func prepareStatement(stmt string) (*statement, []marker, error) {
+	binder := &binder{preparing: true}
+	s, err := parse(stmt, binder)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, binder.markers, nil
+}
+
+func parse(stmt string, binder *binder) (*statement, error) {
+	m := selectPattern.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil, syntaxError("unsupported statement: %s", stmt)
+	}
+
+	s := &statement{table: strings.ToLower(strings.ReplaceAll(m[2], `"`, ""))}
+
+	// Selected columns
+	selection := strings.TrimSpace(m[1])
+	switch {
+	case strings.EqualFold(strings.ReplaceAll(selection, " ", ""), "COUNT(*)"):
+		s.count = true
+	case selection != "*":
+		for _, c := range splitTop(selection, ",") {
+			s.columns = append(s.columns, identifier(c))
+		}
+	}
+
+	// WHERE relations
+	if m[3] != "" {
+		for _, part := range splitTop(m[3], "AND") {
+			r, err := parseRelation(part, binder)
+			if err != nil {
+				return nil, err
+			}
+			s.relations = append(s.relations, r)
+		}
+	}
+
+	// ORDER BY
+	if m[4] != "" {
+		for _, part := range splitTop(m[4], ",") {
+			fields := strings.Fields(part)
+			o := ordering{column: identifier(fields[0])}
+			if len(fields) > 1 && strings.EqualFold(fields[1], "DESC") {
+				o.descending = true
+			}
+			s.orderBy = append(s.orderBy, o)
+		}
+	}
+
+	// LIMIT
+	if m[5] != "" {
+		binder.column = marker{}
+		v, err := binder.value(m[5])
+		if err != nil {
+			return nil, err
+		}
+		n, ok := toInt(v)
+		if binder.preparing && v == nil {
+			n, ok = 1, true
+		}
+		if !ok || n <= 0 {
+			return nil, invalidError("invalid LIMIT %v", v)
+		}
+		s.limit = int(n)
+	}
+	return s, nil
+}
+
+func parseRelation(text string, b *binder) (relation, error) {
+	m := relationPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return relation{}, syntaxError("unsupported relation: %s", text)
+	}
+
+	r := relation{op: strings.ToUpper(m[2])}
+	left := strings.TrimSpace(m[1])
+	if strings.HasPrefix(strings.ToLower(left), "token") {
+		// token(pk, ...) compares with a single bigint token
+		for _, c := range splitTop(strings.Trim(strings.TrimSpace(left[len("token"):]), "()"), ",") {
+			r.columns = append(r.columns, identifier(c))
+		}
+		if r.op == "IN" || r.op == "CONTAINS" {
+			return relation{}, invalidError("unsupported token relation: %s", text)
+		}
+		r.token = true
+		b.column = marker{token: true}
+		value, err := b.value(m[3])
+		if err != nil {
+			return relation{}, err
+		}
+		r.values = []interface{}{value}
+		return r, nil
+	}
+	if strings.HasPrefix(left, "(") {
+		for _, c := range splitTop(strings.Trim(left, "()"), ",") {
+			r.columns = append(r.columns, identifier(c))
+		}
+	} else {
+		r.columns = []string{identifier(left)}
+	}
+
+	right := strings.TrimSpace(m[3])
+	switch {
+	case strings.HasPrefix(right, "("):
+		for i, v := range splitTop(strings.TrimSuffix(strings.TrimPrefix(right, "("), ")"), ",") {
+			b.column = marker{column: r.columns[min(i, len(r.columns)-1)]}
+			value, err := b.value(v)
+			if err != nil {
+				return relation{}, err
+			}
+			r.values = append(r.values, value)
+		}
+	case r.op == "IN":
+		// "IN ?" binds a whole list
+		b.column = marker{column: r.columns[0], list: true}
+		value, err := b.value(right)
+		if err != nil {
+			return relation{}, err
+		}
+		r.values = toSlice(value)
+	default:
+		b.column = marker{column: r.columns[0]}
+		value, err := b.value(right)
+		if err != nil {
+			return relation{}, err
+		}
+		r.values = []interface{}{value}
+	}
+
+	if len(r.columns) > 1 && len(r.columns) != len(r.values) {
+		return relation{}, invalidError("tuple %s has %d values", left, len(r.values))
+	}
+	return r, nil
+}
+
+// apply filters, sorts, limits and projects the rows.
+func (s *statement) apply(rows []map[string]interface{}) ([]map[string]interface{}, error) {
+	matched := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		if s.matches(row) {
+			matched = append(matched, row)
+		}
+	}
+
+	if len(s.orderBy) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			for _, o := range s.orderBy {
+				c := core.CompareValues(matched[i][o.column], matched[j][o.column])
+				if o.descending {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	if s.limit > 0 && len(matched) > s.limit {
+		matched = matched[:s.limit]
+	}
+
+	if s.count {
+		return []map[string]interface{}{{"count": int64(len(matched))}}, nil
+	}
+	if s.columns == nil {
+		return matched, nil
+	}
+
+	projected := make([]map[string]interface{}, len(matched))
+	for i, row := range matched {
+		p := make(map[string]interface{}, len(s.columns))
+		for _, c := range s.columns {
+			p[c] = row[c]
+		}
+		projected[i] = p
+	}
+	return projected, nil
+}
+
+func (s *statement) matches(row map[string]interface{}) bool {
+	for _, r := range s.relations {
+		if !r.matches(row) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r relation) matches(row map[string]interface{}) bool {
+	switch r.op {
+	case "IN":
+		for _, v := range r.values {
+			if core.CompareValues(row[r.columns[0]], v) == 0 {
+				return true
+			}
+		}
+		return false
+	case "CONTAINS":
+		for _, v := range toSlice(row[r.columns[0]]) {
+			if core.CompareValues(v, r.values[0]) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Token of the partition key, or a single column or tuple compared lexicographically
+	c := 0
+	if r.token {
+		key := make([]interface{}, len(r.columns))
+		for i, column := range r.columns {
+			key[i] = row[column]
+		}
+		c = core.CompareValues(Token(key...), r.values[0])
+	} else {
+		for i, column := range r.columns {
+			if c = core.CompareValues(row[column], r.values[i]); c != 0 {
+				break
+			}
+		}
+	}
+
+	switch r.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+// binder hands out bind values in marker order. While preparing it records the
+// column each marker is compared with instead.
+type binder struct {
+	values    []interface{}
+	next      int
+	preparing bool
+	column    marker   // column of the value being parsed
+	markers   []marker // columns of the markers seen, in order
+}
+
+// marker describes what a bind marker binds: a column value, a list of them
+// ("IN ?"), a partition key token, or the LIMIT when column is empty.
+type marker struct {
+	column string
+	list   bool
+	token  bool
+}
+
+// value resolves a bind marker or a literal.
+func (b *binder) value(text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "?":
+		b.markers = append(b.markers, b.column)
+		if b.next >= len(b.values) {
+			b.next++
+			return nil, nil
+		}
+		v := b.values[b.next]
+		b.next++
+		return v, nil
+	case strings.HasPrefix(text, "'") && strings.HasSuffix(text, "'") && len(text) >= 2:
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case strings.EqualFold(text, "null"):
+		return nil, nil
+	case strings.EqualFold(text, "true"), strings.EqualFold(text, "false"):
+		return strings.EqualFold(text, "true"), nil
+	}
+
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return nil, syntaxError("unsupported value: %s", text)
+}
+
+// splitTop splits text on sep (a keyword such as AND, or a single character)
+// outside parentheses and quotes.
+func splitTop(text, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	quoted := false
+	keyword := len(sep) > 1
+
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\'':
+			quoted = !quoted
+		case quoted:
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && !keyword && text[i:i+1] == sep:
+			parts = append(parts, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		case depth == 0 && keyword && i+len(sep) <= len(text) && strings.EqualFold(text[i:i+len(sep)], sep) &&
+			i > 0 && isSpace(text[i-1]) && i+len(sep) < len(text) && isSpace(text[i+len(sep)]):
+			parts = append(parts, strings.TrimSpace(text[start:i]))
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, strings.TrimSpace(text[start:]))
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// identifier normalises a column name: quoted names keep their case.
+func identifier(text string) string {
+	text = strings.TrimSpace(text)
+	if fields := strings.Fields(text); len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+		text = fields[0]
+	}
+	if strings.HasPrefix(text, `"`) {
+		return strings.Trim(text, `"`)
+	}
+	return strings.ToLower(text)
+}
+
+// toSlice expands a bound list (any slice or array) into its elements.
+func toSlice(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return []interface{}{v}
+	}
+	if _, ok := v.([]byte); ok {
+		return []interface{}{v}
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+func toInt(v interface{}) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
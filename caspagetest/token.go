@@ -0,0 +1,103 @@
+package caspagetest
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Token returns the Murmur3 token of a partition key, as Cassandra computes it
+// for token() relations: the hash of the serialised key, with each component
+// of a composite key framed by its length and a trailing zero byte.
+func Token(key ...interface{}) int64 {
+	var b []byte
+	if len(key) == 1 {
+		b, _ = encodeValue(typeOf(key[0]), key[0])
+	} else {
+		for _, v := range key {
+			component, _ := encodeValue(typeOf(v), v)
+			b = binary.BigEndian.AppendUint16(b, uint16(len(component)))
+			b = append(b, component...)
+			b = append(b, 0)
+		}
+	}
+	token := murmur3(b)
+	if token == math.MinInt64 {
+		// the minimum token is reserved for the start of the ring
+		return math.MaxInt64
+	}
+	return token
+}
+
+// murmur3 is the first half of Cassandra's MurmurHash3 x64 128-bit variant,
+// including its sign extension of tail bytes.
+func murmur3(data []byte) int64 {
+	const (
+		c1 = -8663945395140668459 // 0x87c37b91114253d5
+		c2 = 5545529020109919103  // 0x4cf5ad432745937f
+	)
+	var h1, h2 int64
+
+	blocks := len(data) / 16
+	for i := 0; i < blocks; i++ {
+		k1 := int64(binary.LittleEndian.Uint64(data[i*16:]))
+		k2 := int64(binary.LittleEndian.Uint64(data[i*16+8:]))
+
+		k1 *= c1
+		k1 = rotl(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+		h1 = rotl(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+
+		k2 *= c2
+		k2 = rotl(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+		h2 = rotl(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+
+	tail := data[blocks*16:]
+	var k1, k2 int64
+	for i := len(tail) - 1; i >= 8; i-- {
+		k2 ^= int64(int8(tail[i])) << (8 * (i - 8))
+	}
+	if len(tail) > 8 {
+		k2 *= c2
+		k2 = rotl(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+	}
+	for i := min(len(tail), 8) - 1; i >= 0; i-- {
+		k1 ^= int64(int8(tail[i])) << (8 * i)
+	}
+	if len(tail) > 0 {
+		k1 *= c1
+		k1 = rotl(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+	}
+
+	h1 ^= int64(len(data))
+	h2 ^= int64(len(data))
+	h1 += h2
+	h2 += h1
+	h1 = fmix(h1)
+	h2 = fmix(h2)
+	return h1 + h2
+}
+
+func rotl(x int64, r uint) int64 {
+	return x<<r | int64(uint64(x)>>(64-r))
+}
+
+func fmix(k int64) int64 {
+	k ^= int64(uint64(k) >> 33)
+	k *= -49064778989728563 // 0xff51afd7ed558ccd
+	k ^= int64(uint64(k) >> 33)
+	k *= -4265267296055464877 // 0xc4ceb9fe1a85ec53
+	k ^= int64(uint64(k) >> 33)
+	return k
+}
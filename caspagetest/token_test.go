@@ -0,0 +1,92 @@
+package caspagetest_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/gocql/gocql"
+)
+
+func TestToken_Murmur3(t *testing.T) {
+	// Tokens of text keys, as Cassandra's Murmur3Partitioner computes them
+	vectors := map[string]uint64{
+		"":             0,
+		"hello":        0xcbd8a7b341bd9b02,
+		"hello, world": 0x342fac623a5ebc8e,
+		"The quick brown fox jumps over the lazy dog.": 0xcd99481f9ee902c9,
+		"0":                   0x2ac9debed546a380,
+		"0123456789012345678": 0x2d0338c1ca87d132,
+	}
+	for key, want := range vectors {
+		if got := uint64(caspagetest.Token(key)); got != want {
+			t.Errorf("Token(%q) = %#x, want %#x", key, got, want)
+		}
+	}
+
+	if caspagetest.Token("a", int64(1)) == caspagetest.Token("a") {
+		t.Error("expected a composite key to hash differently from its first component")
+	}
+}
+
+// devices builds one row for each of n partitions.
+func devices(n int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"device": fmt.Sprintf("d%d", i), "ts": int64(i)}
+	}
+	return rows
+}
+
+func TestSession_TokenRange(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("ks.devices", devices(40))
+	srv, err := caspagetest.NewServer(s)
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	defer srv.Close()
+
+	var want []int64
+	for _, row := range devices(40) {
+		if caspagetest.Token(row["device"]) > 0 {
+			want = append(want, row["ts"].(int64))
+		}
+	}
+	if len(want) == 0 || len(want) == 40 {
+		t.Fatalf("expected the range to split the partitions, got %d of 40", len(want))
+	}
+
+	// In memory
+	iter := s.Query("SELECT ts FROM ks.devices WHERE token(device) > ? AND token(device) <= ?", int64(0), int64(math.MaxInt64)).Iter()
+	var got []int64
+	row := map[string]interface{}{}
+	for iter.MapScan(row) {
+		got = append(got, row["ts"].(int64))
+	}
+	if err := iter.Close(); err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v (%v)", want, got, err)
+	}
+
+	// Through a driver, which binds the bounds as the bigint token type
+	cluster := gocql.NewCluster(srv.Addr())
+	cluster.ProtoVersion = 4
+	cluster.Timeout = 5 * time.Second
+	session, err := cluster.CreateSession()
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	driverIter := session.Query("SELECT ts FROM ks.devices WHERE token(device) > ? AND token(device) <= ?", int64(0), int64(math.MaxInt64)).Iter()
+	got = nil
+	var ts int64
+	for driverIter.Scan(&ts) {
+		got = append(got, ts)
+	}
+	if err := driverIter.Close(); err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("driver: expected %v, got %v (%v)", want, got, err)
+	}
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveFetch lets the paginator tune the driver fetch size — the number of
+// rows Cassandra returns per round trip — while pages keep Options.PageSize rows.
+// After every page the fetch size moves halfway towards the size that would
+// meet both targets, based on the observed time per row and row width.
+type AdaptiveFetch struct {
+	MinFetchSize  int           // lower bound (default: PageSize/10, at least 1)
+	MaxFetchSize  int           // upper bound (default: PageSize)
+	TargetLatency time.Duration // desired duration of one driver fetch (default: 100ms)
+	TargetBytes   int           // desired approximate size of one driver fetch (default: 1 MiB)
+}
+
+// fetchSizer holds the adaptive state of a paginator.
+type fetchSizer struct {
+	mu          sync.Mutex
+	size        int
+	bytesPerRow float64
+}
+
+// fetchSize returns the driver fetch size to use for the next query. With
+// MaxPageBytes it is capped at about one page budget of rows, once the row
+// width is known, so large rows are neither loaded nor re-read past the page.
+func (p *Paginator) fetchSize() int {
+	sizer := p.sizerState()
+	sizer.mu.Lock()
+	defer sizer.mu.Unlock()
+
+	size := p.PageSize
+	if p.Opts.Adaptive != nil {
+		size = sizer.current(p)
+	}
+	if p.Opts.MaxPageBytes > 0 && sizer.bytesPerRow > 0 {
+		budgetRows := int(math.Ceil(float64(p.Opts.MaxPageBytes) / sizer.bytesPerRow))
+		size = clampInt(budgetRows, 1, size)
+	}
+	return size
+}
+
+// sizerState returns the fetch size state, shared with the Definition the
+// paginator was derived from.
+func (p *Paginator) sizerState() *fetchSizer {
+	if p.parent != nil {
+		return &p.parent.sizer
+	}
+	return &p.sizer
+}
+
+// fetchBounds returns the configured or default fetch size bounds.
+func (p *Paginator) fetchBounds() (int, int) {
+	a := p.Opts.Adaptive
+	minSize, maxSize := a.MinFetchSize, a.MaxFetchSize
+	if minSize <= 0 {
+		minSize = max(p.PageSize/10, 1)
+	}
+	if maxSize <= 0 {
+		maxSize = p.PageSize
+	}
+	return minSize, max(minSize, maxSize)
+}
+
+// adaptFetchSize records a fetch of rows (scanned rows, including skipped ones)
+// that took duration and picks the next fetch size.
+func (p *Paginator) adaptFetchSize(rows []map[string]interface{}, scanned int, duration time.Duration) {
+	a := p.Opts.Adaptive
+	if a == nil {
+		// MaxPageBytes still needs the row width to cap the fetch size
+		if p.Opts.MaxPageBytes > 0 {
+			sizer := p.sizerState()
+			sizer.mu.Lock()
+			sizer.observeRows(rows)
+			sizer.mu.Unlock()
+		}
+		return
+	}
+	if scanned == 0 {
+		return
+	}
+	minSize, maxSize := p.fetchBounds()
+
+	targetLatency := a.TargetLatency
+	if targetLatency <= 0 {
+		targetLatency = 100 * time.Millisecond
+	}
+	targetBytes := a.TargetBytes
+	if targetBytes <= 0 {
+		targetBytes = 1 << 20
+	}
+
+	sizer := p.sizerState()
+	sizer.mu.Lock()
+	previous := sizer.current(p)
+
+	sizer.observeRows(rows)
+	bytesPerRow := sizer.bytesPerRow
+
+	target := maxSize
+	if perRow := duration / time.Duration(scanned); perRow > 0 {
+		target = min(target, int(targetLatency/perRow))
+	}
+	if bytesPerRow > 0 {
+		target = min(target, int(float64(targetBytes)/bytesPerRow))
+	}
+	size := clampInt((previous+target)/2, minSize, maxSize)
+	sizer.size = size
+	sizer.mu.Unlock()
+
+	if size != previous {
+		p.Opts.log("fetch_size_adjusted", map[string]interface{}{
+			"fetch_size":    size,
+			"previous":      previous,
+			"bytes_per_row": int(bytesPerRow),
+			"duration_ms":   duration.Milliseconds(),
+		})
+	}
+	if o, ok := p.Opts.Metrics.(FetchSizeObserver); ok {
+		o.ObserveFetchSize(size, int(bytesPerRow))
+	}
+}
+
+// current returns the adaptive fetch size within the bounds of p. Paginators
+// derived from one Definition share the size but may differ in page size, so
+// it is clamped on every read. The caller holds s.mu.
+func (s *fetchSizer) current(p *Paginator) int {
+	minSize, maxSize := p.fetchBounds()
+	if s.size == 0 {
+		return clampInt(p.PageSize, minSize, maxSize)
+	}
+	return clampInt(s.size, minSize, maxSize)
+}
+
+// observeRows folds the width of rows into bytesPerRow, smoothed so a single
+// odd page does not swing the size. The caller holds s.mu.
+func (s *fetchSizer) observeRows(rows []map[string]interface{}) {
+	if len(rows) == 0 {
+		return
+	}
+	width := 0
+	for _, row := range rows {
+		width += rowBytes(row)
+	}
+	observed := float64(width) / float64(len(rows))
+	if s.bytesPerRow == 0 {
+		s.bytesPerRow = observed
+	} else {
+		s.bytesPerRow = (s.bytesPerRow + observed) / 2
+	}
+}
+
+// rowBytes approximates the in-memory size of a row's column names and values.
+func rowBytes(row map[string]interface{}) int {
+	n := 0
+	for k, v := range row {
+		n += len(k)
+		switch v := v.(type) {
+		case nil:
+		case string:
+			n += len(v)
+		case []byte:
+			n += len(v)
+		case bool, int8, uint8:
+			n++
+		case int16, uint16:
+			n += 2
+		case int32, uint32, float32:
+			n += 4
+		case int, int64, uint, uint64, float64, time.Duration:
+			n += 8
+		case time.Time:
+			n += 12
+		default:
+			n += 16 // uuids, decimals, collections: a rough guess
+		}
+	}
+	return n
+}
+
+func clampInt(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
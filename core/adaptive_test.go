@@ -0,0 +1,169 @@
+package core_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// fetchSizeRecorder records the fetch sizes reported to the optional FetchSizeObserver.
+type fetchSizeRecorder struct {
+	mu    sync.Mutex
+	sizes []int
+	bytes int
+}
+
+func (r *fetchSizeRecorder) ObservePageFetch(int, time.Duration) {}
+func (r *fetchSizeRecorder) ObserveError(error)                  {}
+
+func (r *fetchSizeRecorder) ObserveFetchSize(size, bytesPerRow int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sizes = append(r.sizes, size)
+	r.bytes = bytesPerRow
+}
+
+// walkIDs pages through p and returns the ids of all rows and the page lengths.
+func walkIDs(t *testing.T, p *core.Paginator) ([]int, []int) {
+	t.Helper()
+	var ids, lengths []int
+	token := ""
+	for i := 0; i < 100; i++ {
+		rows, next, err := p.NextWithToken(token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, rowIDs(rows)...)
+		lengths = append(lengths, len(rows))
+		env, _ := core.DecodeToken(next)
+		if len(env.State) == 0 && env.Skip == 0 {
+			break
+		}
+		token = next
+	}
+	return ids, lengths
+}
+
+func assertSequence(t *testing.T, ids []int, n int) {
+	t.Helper()
+	if len(ids) != n {
+		t.Fatalf("expected %d rows, got %d: %v", n, len(ids), ids)
+	}
+	for i, id := range ids {
+		if id != i {
+			t.Fatalf("row %d has id %d: %v", i, id, ids)
+		}
+	}
+}
+
+func TestAdaptive_ShrinksFetchSizeForWideRows(t *testing.T) {
+	rows := numberedRows(60)
+	for _, row := range rows {
+		row["payload"] = strings.Repeat("x", 1000)
+	}
+
+	recorder := &fetchSizeRecorder{}
+	p := core.NewPaginator(newTableSession("blobs", rows), "SELECT * FROM blobs", core.Options{
+		PageSize: 10,
+		Metrics:  recorder,
+		Adaptive: &core.AdaptiveFetch{MinFetchSize: 3, TargetBytes: 4000},
+	})
+
+	ids, lengths := walkIDs(t, p)
+	assertSequence(t, ids, 60)
+	for i, n := range lengths[:len(lengths)-1] {
+		if n != 10 {
+			t.Errorf("page %d has %d rows, expected a stable page size of 10", i, n)
+		}
+	}
+
+	last := recorder.sizes[len(recorder.sizes)-1]
+	if last != 3 {
+		t.Errorf("expected the fetch size to settle at the 4000/~1000 byte bound (min 3), got %v", recorder.sizes)
+	}
+	if recorder.bytes < 1000 {
+		t.Errorf("expected ~1000 bytes per row, got %d", recorder.bytes)
+	}
+}
+
+func TestAdaptive_GrowsFetchSizeForNarrowRows(t *testing.T) {
+	recorder := &fetchSizeRecorder{}
+	p := core.NewPaginator(newTableSession("users", numberedRows(95)), "SELECT * FROM users", core.Options{
+		PageSize: 10,
+		Metrics:  recorder,
+		Adaptive: &core.AdaptiveFetch{MaxFetchSize: 40},
+	})
+
+	ids, _ := walkIDs(t, p)
+	assertSequence(t, ids, 95)
+
+	if last := recorder.sizes[len(recorder.sizes)-1]; last <= 10 || last > 40 {
+		t.Errorf("expected the fetch size to grow towards 40, got %v", recorder.sizes)
+	}
+}
+
+func TestAdaptive_RespectsLatencyTarget(t *testing.T) {
+	recorder := &fetchSizeRecorder{}
+	session := &slowSession{Session: newTableSession("users", numberedRows(50)), perRow: time.Millisecond}
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		PageSize: 20,
+		Metrics:  recorder,
+		Adaptive: &core.AdaptiveFetch{MinFetchSize: 2, TargetLatency: 5 * time.Millisecond},
+	})
+
+	ids, _ := walkIDs(t, p)
+	assertSequence(t, ids, 50)
+
+	if last := recorder.sizes[len(recorder.sizes)-1]; last >= 20 {
+		t.Errorf("expected slow rows to shrink the fetch size, got %v", recorder.sizes)
+	}
+}
+
+// slowSession delays every row scan.
+type slowSession struct {
+	*caspagetest.Session
+	perRow time.Duration
+}
+
+func (s *slowSession) Query(stmt string, args ...interface{}) core.CassandraQuery {
+	return &slowQuery{CassandraQuery: s.Session.Query(stmt, args...), perRow: s.perRow}
+}
+
+type slowQuery struct {
+	core.CassandraQuery
+	perRow time.Duration
+}
+
+func (q *slowQuery) PageSize(n int) core.CassandraQuery {
+	q.CassandraQuery = q.CassandraQuery.PageSize(n)
+	return q
+}
+
+func (q *slowQuery) PageState(b []byte) core.CassandraQuery {
+	q.CassandraQuery = q.CassandraQuery.PageState(b)
+	return q
+}
+
+func (q *slowQuery) WithContext(ctx context.Context) core.CassandraQuery {
+	q.CassandraQuery = q.CassandraQuery.WithContext(ctx)
+	return q
+}
+
+func (q *slowQuery) Iter() core.CassandraIter {
+	return &slowIter{CassandraIter: q.CassandraQuery.Iter(), perRow: q.perRow}
+}
+
+type slowIter struct {
+	core.CassandraIter
+	perRow time.Duration
+}
+
+func (i *slowIter) MapScan(m map[string]interface{}) bool {
+	time.Sleep(i.perRow)
+	return i.CassandraIter.MapScan(m)
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BucketGenerator returns the buckets of a time-series table in ascending order.
+// Any function works; DailyBuckets and HourlyBuckets cover the common layouts.
+type BucketGenerator func() []interface{}
+
+// DailyBuckets generates one bucket per calendar day from "from" to "to" (inclusive),
+// in from's location. With an empty layout buckets are time.Time values at midnight
+// (suitable for date/timestamp columns); otherwise they are formatted with layout.
+func DailyBuckets(from, to time.Time, layout string) BucketGenerator {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	return timeBuckets(start, to, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }, layout)
+}
+
+// HourlyBuckets generates one bucket per hour from "from" to "to" (inclusive).
+func HourlyBuckets(from, to time.Time, layout string) BucketGenerator {
+	return timeBuckets(from.Truncate(time.Hour), to, func(t time.Time) time.Time { return t.Add(time.Hour) }, layout)
+}
+
+func timeBuckets(start, to time.Time, next func(time.Time) time.Time, layout string) BucketGenerator {
+	return func() []interface{} {
+		buckets := []interface{}{}
+		for t := start; !t.After(to); t = next(t) {
+			if layout == "" {
+				buckets = append(buckets, t)
+			} else {
+				buckets = append(buckets, t.Format(layout))
+			}
+		}
+		return buckets
+	}
+}
+
+// BucketPaginator pages over a table partitioned by time buckets, e.g. (device_id, day).
+// It restricts the bucket column to one bucket at a time, walks the buckets in
+// ascending (or descending) order, skips empty ones and fills each page across
+// bucket boundaries. The token records the current bucket and the page state inside it.
+type BucketPaginator struct {
+	Session    CassandraSession
+	Query      string
+	Column     string // bucket column, part of the partition key
+	Buckets    BucketGenerator
+	Descending bool // walk buckets from newest to oldest
+	PageSize   int
+	Opts       Options
+}
+
+// bucketToken is the position of a BucketPaginator.
+type bucketToken struct {
+	Index int        `json:"bucket"`
+	Value TypedValue `json:"value"`
+	State []byte     `json:"state,omitempty"`
+	Skip  int        `json:"skip,omitempty"`
+	Prev  string     `json:"prev,omitempty"`
+}
+
+// NewBucketPaginator creates a paginator walking the buckets of column.
+func NewBucketPaginator(session CassandraSession, query, column string, buckets BucketGenerator, opts Options) *BucketPaginator {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	return &BucketPaginator{
+		Session:  session,
+		Query:    query,
+		Column:   column,
+		Buckets:  buckets,
+		PageSize: pageSize,
+		Opts:     opts,
+	}
+}
+
+// Next fetches the first page.
+func (b *BucketPaginator) Next() ([]map[string]interface{}, string, error) {
+	return b.NextWithToken("")
+}
+
+// NextContext is Next with a per-call context, used instead of Options.Context.
+func (b *BucketPaginator) NextContext(ctx context.Context) ([]map[string]interface{}, string, error) {
+	return b.NextWithTokenContext(ctx, "")
+}
+
+// NextWithToken fetches the page at the given token.
+func (b *BucketPaginator) NextWithToken(token string) ([]map[string]interface{}, string, error) {
+	return b.NextWithTokenContext(b.Opts.context(), token)
+}
+
+// NextWithTokenContext is NextWithToken with a per-call context, used instead of Options.Context.
+func (b *BucketPaginator) NextWithTokenContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	buckets := b.buckets()
+
+	var pos bucketToken
+	if err := decodeJSONToken(token, &pos); err != nil {
+		b.Opts.observeInvalidToken(token, err)
+		return nil, "", ErrInvalidToken
+	}
+
+	// 1️⃣ Make sure the token still points at the same bucket
+	if token != "" {
+		if pos.Index < 0 || pos.Index >= len(buckets) {
+			b.Opts.observeInvalidToken(token, fmt.Errorf("bucket %d out of range", pos.Index))
+			return nil, "", ErrInvalidToken
+		}
+		value, err := pos.Value.Decode()
+		if err != nil || compareValues(value, buckets[pos.Index]) != 0 {
+			b.Opts.observeInvalidToken(token, fmt.Errorf("bucket %d changed", pos.Index))
+			return nil, "", ErrInvalidToken
+		}
+	}
+
+	// 2️⃣ Fill the page bucket by bucket
+	start := time.Now()
+	index, state, skip := pos.Index, pos.State, pos.Skip
+	visited := 0
+	results := []map[string]interface{}{}
+
+	for index < len(buckets) && len(results) < b.PageSize {
+		remaining := b.PageSize - len(results)
+		rows, next, nextSkip, err := b.bucketPaginator(buckets[index], remaining).fetchState(ctx, state, skip)
+		if err != nil {
+			return nil, "", err
+		}
+		results = append(results, rows...)
+		visited++
+
+		if len(next) == 0 && nextSkip == 0 {
+			// bucket exhausted (or empty), continue with the next one
+			index, state, skip = index+1, nil, 0
+			continue
+		}
+		state, skip = next, nextSkip
+	}
+
+	b.Opts.log("buckets_walked", map[string]interface{}{
+		"buckets_visited": visited,
+		"rows_fetched":    len(results),
+		"duration_ms":     time.Since(start).Milliseconds(),
+	})
+
+	// 3️⃣ Encode the current bucket and the page state inside it
+	if index >= len(buckets) {
+		return results, "", nil
+	}
+	value, err := NewTypedValue(buckets[index])
+	if err != nil {
+		return nil, "", err
+	}
+	return results, encodeJSONToken(bucketToken{Index: index, Value: value, State: state, Skip: skip, Prev: token}), nil
+}
+
+// Previous fetches the page before the one the token leads to, using the embedded previous token.
+func (b *BucketPaginator) Previous(token string) ([]map[string]interface{}, string, error) {
+	return b.PreviousContext(b.Opts.context(), token)
+}
+
+// PreviousContext is Previous with a per-call context, used instead of Options.Context.
+func (b *BucketPaginator) PreviousContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	var pos bucketToken
+	if err := decodeJSONToken(token, &pos); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if pos.Prev == "" {
+		return nil, "", ErrNoPrevToken
+	}
+	return b.NextWithTokenContext(ctx, pos.Prev)
+}
+
+// buckets returns the buckets in walk order.
+func (b *BucketPaginator) buckets() []interface{} {
+	if b.Buckets == nil {
+		return nil
+	}
+	buckets := b.Buckets()
+	if b.Descending {
+		for i, j := 0, len(buckets)-1; i < j; i, j = i+1, j-1 {
+			buckets[i], buckets[j] = buckets[j], buckets[i]
+		}
+	}
+	return buckets
+}
+
+// bucketPaginator returns a paginator restricted to one bucket, reading at most pageSize rows.
+func (b *BucketPaginator) bucketPaginator(bucket interface{}, pageSize int) *Paginator {
+	opts := b.Opts
+	opts.Filters = make(map[string]interface{}, len(b.Opts.Filters)+1)
+	for k, v := range b.Opts.Filters {
+		opts.Filters[k] = v
+	}
+	opts.Filters[b.Column] = bucket
+	opts.PageSize = pageSize
+
+	return NewPaginator(b.Session, b.Query, opts)
+}
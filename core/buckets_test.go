@@ -0,0 +1,129 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// readingsSession holds the readings of device d1 per day, ordered by ts.
+func readingsSession(days map[string][]int) *caspagetest.Session {
+	var rows []map[string]interface{}
+	for _, day := range []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04"} {
+		for _, ts := range days[day] {
+			rows = append(rows, map[string]interface{}{"device_id": "d1", "day": day, "ts": ts})
+		}
+	}
+	s := caspagetest.NewSession()
+	s.AddTable("readings", rows)
+	return s
+}
+
+func TestDailyBuckets(t *testing.T) {
+	from := time.Date(2024, 2, 28, 15, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	got := core.DailyBuckets(from, to, "2006-01-02")()
+	want := []interface{}{"2024-02-28", "2024-02-29", "2024-03-01"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected buckets: %v", got)
+	}
+
+	if n := len(core.HourlyBuckets(from, from.Add(2*time.Hour), "")()); n != 3 {
+		t.Fatalf("expected 3 hourly buckets, got %d", n)
+	}
+}
+
+func TestBucketPaginator(t *testing.T) {
+	session := readingsSession(map[string][]int{
+		"2024-03-01": {1, 2, 3},
+		"2024-03-03": {4, 5, 6, 7, 8},
+		"2024-03-04": {9},
+	})
+	buckets := core.DailyBuckets(
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		"2006-01-02",
+	)
+
+	walk := func(b *core.BucketPaginator) [][]int {
+		var pages [][]int
+		token := ""
+		for i := 0; i < 10; i++ {
+			rows, next, err := b.NextWithToken(token)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pages = append(pages, timestamps(rows))
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		return pages
+	}
+
+	asc := core.NewBucketPaginator(session, "SELECT * FROM readings", "day", buckets, core.Options{
+		PageSize: 4,
+		Filters:  map[string]interface{}{"device_id": "d1"},
+	})
+	if got := walk(asc); !reflect.DeepEqual(got, [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9}}) {
+		t.Errorf("unexpected ascending pages: %v", got)
+	}
+
+	desc := core.NewBucketPaginator(session, "SELECT * FROM readings", "day", buckets, core.Options{PageSize: 4})
+	desc.Descending = true
+	if got := walk(desc); !reflect.DeepEqual(got, [][]int{{9, 4, 5, 6}, {7, 8, 1, 2}, {3}}) {
+		t.Errorf("unexpected descending pages: %v", got)
+	}
+
+	// A token from another bucket layout is rejected
+	_, token, _ := asc.Next()
+	other := core.NewBucketPaginator(session, "SELECT * FROM readings", "day",
+		core.DailyBuckets(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), "2006-01-02"),
+		core.Options{PageSize: 4})
+	if _, _, err := other.NextWithToken(token); err == nil {
+		t.Error("expected error for token from a different bucket layout")
+	}
+}
+
+func TestBucketPaginator_PerCallContext(t *testing.T) {
+	session := readingsSession(map[string][]int{
+		"2024-03-01": {1, 2, 3},
+		"2024-03-03": {4, 5, 6, 7, 8},
+	})
+	b := core.NewBucketPaginator(session, "SELECT * FROM readings", "day", core.DailyBuckets(
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		"2006-01-02",
+	), core.Options{PageSize: 3})
+
+	rows, token, err := b.NextContext(context.Background())
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d, err %v", len(rows), err)
+	}
+	_, next, err := b.NextWithTokenContext(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := b.NextWithTokenContext(ctx, token); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from NextWithTokenContext, got %v", err)
+	}
+	if _, _, err := b.PreviousContext(ctx, next); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from PreviousContext, got %v", err)
+	}
+
+	// Options.Context is not consulted by the per-call variants
+	b.Opts.Context = ctx
+	if rows, _, err := b.PreviousContext(context.Background(), next); err != nil || !reflect.DeepEqual(timestamps(rows), []int{4, 5, 6}) {
+		t.Errorf("expected the second page, got %v (%v)", timestamps(rows), err)
+	}
+}
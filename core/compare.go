@@ -0,0 +1,119 @@
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CompareValues orders two column values as Cassandra would; it is the ordering
+// used by merged paginators and keyset cursors. It returns -1, 0 or 1.
+func CompareValues(a, b interface{}) int {
+	return compareValues(a, b)
+}
+
+// compareValues orders two column values as Cassandra would for the common CQL types.
+// It returns -1, 0 or 1. nil sorts first; values of unrelated types are compared
+// by their string form so the ordering stays deterministic.
+func compareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	// Numbers of any width compare by value
+	if x, ok := asInt64(a); ok {
+		if y, ok := asInt64(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := asFloat64(a); ok {
+		if y, ok := asFloat64(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			default:
+				return 1
+			}
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	case *big.Int:
+		if y, ok := b.(*big.Int); ok {
+			return x.Cmp(y)
+		}
+	case gocql.UUID:
+		if y, ok := b.(gocql.UUID); ok {
+			// timeuuids order by their timestamp first
+			if x.Version() == 1 && y.Version() == 1 {
+				if c := x.Time().Compare(y.Time()); c != 0 {
+					return c
+				}
+			}
+			return bytes.Compare(x.Bytes(), y.Bytes())
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func asInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case time.Duration:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func asFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	if i, ok := asInt64(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
@@ -0,0 +1,81 @@
+package core
+
+import "github.com/gocql/gocql"
+
+// Consistency is a Cassandra consistency level. The zero value keeps the
+// session default.
+type Consistency uint8
+
+const (
+	ConsistencyDefault Consistency = iota
+	ConsistencyAny
+	ConsistencyOne
+	ConsistencyTwo
+	ConsistencyThree
+	ConsistencyQuorum
+	ConsistencyAll
+	ConsistencyLocalQuorum
+	ConsistencyEachQuorum
+	ConsistencyLocalOne
+	ConsistencySerial
+	ConsistencyLocalSerial
+)
+
+var consistencyLevels = map[Consistency]gocql.Consistency{
+	ConsistencyAny:         gocql.Any,
+	ConsistencyOne:         gocql.One,
+	ConsistencyTwo:         gocql.Two,
+	ConsistencyThree:       gocql.Three,
+	ConsistencyQuorum:      gocql.Quorum,
+	ConsistencyAll:         gocql.All,
+	ConsistencyLocalQuorum: gocql.LocalQuorum,
+	ConsistencyEachQuorum:  gocql.EachQuorum,
+	ConsistencyLocalOne:    gocql.LocalOne,
+	ConsistencySerial:      gocql.Consistency(gocql.Serial),
+	ConsistencyLocalSerial: gocql.Consistency(gocql.LocalSerial),
+}
+
+func (c Consistency) String() string {
+	if c == ConsistencyDefault {
+		return "DEFAULT"
+	}
+	if level, ok := consistencyLevels[c]; ok {
+		return level.String()
+	}
+	return "UNKNOWN"
+}
+
+// Gocql returns the driver consistency level; ok is false for
+// ConsistencyDefault and unknown values.
+func (c Consistency) Gocql() (gocql.Consistency, bool) {
+	level, ok := consistencyLevels[c]
+	return level, ok
+}
+
+// QueryConfig holds per-query driver settings. Zero fields keep the session
+// defaults.
+type QueryConfig struct {
+	Consistency       Consistency
+	SerialConsistency Consistency // ConsistencySerial or ConsistencyLocalSerial; other levels are ignored
+	Idempotent        bool        // mark queries idempotent, allowing speculative execution
+	DriverRetries     int         // retries by the driver itself, before Options.Retry (0: the session's policy); the v2 driver retries idempotent queries only
+	DefaultTimestamp  *bool       // client-side timestamps on or off
+}
+
+// ConfigurableQuery is an optional extension of CassandraQuery. Queries
+// implementing it receive Options.QueryConfig; others run with their session
+// defaults.
+type ConfigurableQuery interface {
+	Configure(QueryConfig) CassandraQuery
+}
+
+// configure applies Options.QueryConfig to q when it supports it.
+func (p *Paginator) configure(q CassandraQuery) CassandraQuery {
+	if p.Opts.QueryConfig == nil {
+		return q
+	}
+	if cq, ok := q.(ConfigurableQuery); ok {
+		return cq.Configure(*p.Opts.QueryConfig)
+	}
+	return q
+}
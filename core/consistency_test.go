@@ -0,0 +1,118 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/gocql/gocql"
+)
+
+func TestQueryConfig_AppliedToEveryQuery(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(12))
+
+	enabled := true
+	cfg := &core.QueryConfig{
+		Consistency:       core.ConsistencyLocalQuorum,
+		SerialConsistency: core.ConsistencyLocalSerial,
+		Idempotent:        true,
+		DriverRetries:     2,
+		DefaultTimestamp:  &enabled,
+	}
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{PageSize: 5, QueryConfig: cfg})
+
+	_, token, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := p.NextWithToken(token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := p.GoToPage(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.Count(core.CountOptions{Mode: core.CountExact}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Query = "SELECT * FROM shop.users"
+	if _, err := p.Count(core.CountOptions{Mode: core.CountEstimate}); !errors.Is(err, core.ErrEstimateUnavailable) {
+		t.Fatalf("expected no size estimates, got %v", err)
+	}
+
+	for i, q := range s.Queries() {
+		if q.Config.Consistency != core.ConsistencyLocalQuorum || q.Config.SerialConsistency != core.ConsistencyLocalSerial ||
+			!q.Config.Idempotent || q.Config.DriverRetries != 2 || q.Config.DefaultTimestamp == nil {
+			t.Errorf("query %d (%s) ran without the config: %+v", i, q.Statement, q.Config)
+		}
+	}
+}
+
+// simpleSession hides the optional query interfaces of a caspagetest session,
+// like a driver adapter that only implements core.CassandraQuery.
+type simpleSession struct {
+	*caspagetest.Session
+}
+
+func (s simpleSession) Query(stmt string, args ...interface{}) core.CassandraQuery {
+	return &simpleQuery{q: s.Session.Query(stmt, args...)}
+}
+
+type simpleQuery struct {
+	q core.CassandraQuery
+}
+
+func (q *simpleQuery) PageSize(n int) core.CassandraQuery {
+	q.q = q.q.PageSize(n)
+	return q
+}
+
+func (q *simpleQuery) PageState(b []byte) core.CassandraQuery {
+	q.q = q.q.PageState(b)
+	return q
+}
+
+func (q *simpleQuery) WithContext(ctx context.Context) core.CassandraQuery {
+	q.q = q.q.WithContext(ctx)
+	return q
+}
+
+func (q *simpleQuery) Iter() core.CassandraIter { return q.q.Iter() }
+
+func TestQueryConfig_IgnoredBySimpleSessions(t *testing.T) {
+	s := newTableSession("users", numberedRows(7))
+	p := core.NewPaginator(simpleSession{s}, "SELECT * FROM users", core.Options{
+		PageSize:    5,
+		QueryConfig: &core.QueryConfig{Consistency: core.ConsistencyOne},
+	})
+
+	rows, _, err := p.Next()
+	if err != nil || len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d, err %v", len(rows), err)
+	}
+	if cfg := s.Queries()[0].Config; cfg.Consistency != core.ConsistencyDefault {
+		t.Errorf("expected no config on a simple session, got %+v", cfg)
+	}
+}
+
+func TestConsistency_Levels(t *testing.T) {
+	if _, ok := core.ConsistencyDefault.Gocql(); ok {
+		t.Error("ConsistencyDefault should keep the session default")
+	}
+	tests := map[core.Consistency]gocql.Consistency{
+		core.ConsistencyOne:         gocql.One,
+		core.ConsistencyLocalQuorum: gocql.LocalQuorum,
+		core.ConsistencyLocalOne:    gocql.LocalOne,
+		core.ConsistencySerial:      gocql.Consistency(gocql.Serial),
+	}
+	for c, want := range tests {
+		if got, ok := c.Gocql(); !ok || got != want {
+			t.Errorf("%v: expected %v, got %v", c, want, got)
+		}
+	}
+	if got := core.ConsistencyLocalQuorum.String(); got != "LOCAL_QUORUM" {
+		t.Errorf("unexpected name %q", got)
+	}
+}
@@ -0,0 +1,128 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+func TestPaginator_PerCallContext(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(10))
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{PageSize: 5})
+
+	rows, token, err := p.NextContext(context.Background())
+	if err != nil || len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d, err %v", len(rows), err)
+	}
+	_, next, err := p.NextWithTokenContext(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := p.NextWithTokenContext(ctx, token); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from NextWithTokenContext, got %v", err)
+	}
+	if _, _, err := p.PreviousContext(ctx, next); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from PreviousContext, got %v", err)
+	}
+
+	// Options.Context is not consulted by the per-call variants
+	p.Opts.Context = ctx
+	if _, _, err := p.PreviousContext(context.Background(), next); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPaginator_PageTimeout(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(10))
+	s.SetLatency(time.Second)
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{PageSize: 5, PageTimeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	_, _, err := p.Next()
+	var qe *core.QueryError
+	if !errors.Is(err, core.ErrPageTimeout) || !errors.As(err, &qe) || qe.Kind != core.KindTimeout {
+		t.Fatalf("expected a page timeout, got %v", err)
+	}
+	if !core.IsRetryable(qe.Err) {
+		t.Error("page timeouts should be retryable")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("the page timeout was not applied: %v", elapsed)
+	}
+
+	// A sooner caller deadline wins and is not retryable
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	p.Opts.PageTimeout = time.Minute
+	_, _, err = p.NextContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, core.ErrPageTimeout) {
+		t.Errorf("expected the caller deadline, got %v", err)
+	}
+
+	// Retries get a fresh page timeout once the latency clears
+	s.SetLatency(0)
+	p.Opts.PageTimeout = 20 * time.Millisecond
+	p.Opts.Retry = &core.RetryPolicy{BaseDelay: time.Millisecond}
+	if rows, _, err := p.Next(); err != nil || len(rows) != 5 {
+		t.Errorf("expected 5 rows, got %d, err %v", len(rows), err)
+	}
+}
+
+// legacySession implements the interfaces from before WithContext was typed.
+type legacySession struct {
+	*caspagetest.Session
+	contexts []interface{}
+}
+
+func (s *legacySession) Query(stmt string, args ...interface{}) core.LegacyQuery {
+	return &legacyQuery{session: s, q: s.Session.Query(stmt, args...)}
+}
+
+type legacyQuery struct {
+	session *legacySession
+	q       core.CassandraQuery
+}
+
+func (q *legacyQuery) PageSize(n int) core.LegacyQuery {
+	q.q = q.q.PageSize(n)
+	return q
+}
+
+func (q *legacyQuery) PageState(b []byte) core.LegacyQuery {
+	q.q = q.q.PageState(b)
+	return q
+}
+
+func (q *legacyQuery) WithContext(ctx interface{}) core.LegacyQuery {
+	q.session.contexts = append(q.session.contexts, ctx)
+	return q
+}
+
+func (q *legacyQuery) Iter() core.CassandraIter { return q.q.Iter() }
+
+func TestAdaptLegacySession(t *testing.T) {
+	legacy := &legacySession{Session: newTableSession("users", numberedRows(7))}
+	p := core.NewPaginator(core.AdaptLegacySession(legacy), "SELECT * FROM users", core.Options{PageSize: 5})
+
+	ids, _ := walkIDs(t, p)
+	if len(ids) != 7 {
+		t.Fatalf("expected 7 rows, got %v", ids)
+	}
+	if len(legacy.contexts) == 0 {
+		t.Fatal("expected WithContext calls")
+	}
+	for _, ctx := range legacy.contexts {
+		if _, ok := ctx.(context.Context); !ok {
+			t.Errorf("expected a context.Context, got %T", ctx)
+		}
+	}
+}
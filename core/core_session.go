@@ -1,5 +1,7 @@
 package core
 
+import "context"
+
 // CassandraSession defines a minimal interface that both real and mock sessions can implement.
 type CassandraSession interface {
 	Query(string, ...interface{}) CassandraQuery
@@ -8,7 +10,7 @@ type CassandraSession interface {
 type CassandraQuery interface {
 	PageSize(int) CassandraQuery
 	PageState([]byte) CassandraQuery
-	WithContext(interface{}) CassandraQuery
+	WithContext(context.Context) CassandraQuery
 	Iter() CassandraIter
 }
 
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gocql/gocql"
+)
+
+// DriverQuery holds the driver-specific callbacks of a query adapter built on a
+// gocql driver: setters for the driver's query settings. Configure and Diagnose
+// hold the rest, so every adapter applies QueryConfig and diagnostics alike.
+// Levels are v1 driver values; the v2 driver uses the same numbers.
+type DriverQuery struct {
+	Consistency       func(level gocql.Consistency)
+	SerialConsistency func(level gocql.SerialConsistency)
+	Idempotent        func()
+	Retries           func(policy DriverRetry)
+	DefaultTimestamp  func(enabled bool)
+
+	// Observe installs a driver observer calling c.Request for every request,
+	// and then the session's QueryObserver if any.
+	Observe func(c *DiagnosticsCollector)
+	// Trace installs c as the driver tracer.
+	Trace func(c *DiagnosticsCollector)
+}
+
+// Configure applies the consistency levels, idempotency, driver retries and
+// timestamp setting of cfg.
+func (d DriverQuery) Configure(cfg QueryConfig) {
+	if level, ok := cfg.Consistency.Gocql(); ok {
+		d.Consistency(level)
+	}
+	switch cfg.SerialConsistency {
+	case ConsistencySerial:
+		d.SerialConsistency(gocql.Serial)
+	case ConsistencyLocalSerial:
+		d.SerialConsistency(gocql.LocalSerial)
+	}
+	if cfg.Idempotent {
+		d.Idempotent()
+	}
+	if cfg.DriverRetries > 0 {
+		d.Retries(DriverRetry(cfg.DriverRetries))
+	}
+	if cfg.DefaultTimestamp != nil {
+		d.DefaultTimestamp(*cfg.DefaultTimestamp)
+	}
+}
+
+// Diagnose observes the driver requests of the query and traces them when trace
+// is set. The returned collector fills in pd when passed to DriverIter.
+func (d DriverQuery) Diagnose(pd *PageDiagnostics, trace bool) *DiagnosticsCollector {
+	c := NewDiagnosticsCollector(pd)
+	d.Observe(c)
+	if trace {
+		d.Trace(c)
+	}
+	return c
+}
+
+// DriverRetry is the driver retry policy of QueryConfig.DriverRetries, which
+// adapters wrap in their driver's RetryPolicy. Timeouts are retried on the same
+// coordinator, which answered but whose replicas were slow; other errors on the
+// next host.
+type DriverRetry int
+
+// Retry reports whether a query tried attempts times may be retried.
+func (n DriverRetry) Retry(attempts int) bool {
+	return attempts <= int(n)
+}
+
+// SameHost reports whether err is retried on the coordinator that returned it.
+func (n DriverRetry) SameHost(err error) bool {
+	var coded interface{ Code() int }
+	return errors.As(err, &coded) && (coded.Code() == gocql.ErrCodeReadTimeout || coded.Code() == gocql.ErrCodeWriteTimeout)
+}
+
+// GocqlIter is the iterator API both gocql drivers share.
+type GocqlIter interface {
+	MapScan(m map[string]interface{}) bool
+	PageState() []byte
+	Warnings() []string
+	Close() error
+}
+
+// DriverIter is what an iterator adapter built on a gocql driver keeps across
+// driver pages. The driver replaces an iterator's warnings with every driver
+// page, so adapters scan, read warnings and close through it. The zero value
+// is ready to use.
+type DriverIter struct {
+	Diagnostics *DiagnosticsCollector // from DriverQuery.Diagnose, on sampled pages
+	warnings    []string
+	state       []byte // page state of the driver page whose warnings were read
+}
+
+// MapScan reads the next row of it, keeping the warnings of the driver page it
+// came from.
+func (d *DriverIter) MapScan(it GocqlIter, m map[string]interface{}) bool {
+	ok := it.MapScan(m)
+	if s := it.PageState(); !ok || !bytes.Equal(s, d.state) {
+		d.warnings = appendNew(d.warnings, it.Warnings())
+		d.state = s
+	}
+	return ok
+}
+
+// Warnings returns the server warnings of the driver pages of it read so far.
+func (d *DriverIter) Warnings(it GocqlIter) []string {
+	d.warnings = appendNew(d.warnings, it.Warnings())
+	return d.warnings
+}
+
+// Close closes it, flushing the diagnostics of sampled pages.
+func (d *DriverIter) Close(it GocqlIter) error {
+	if d.Diagnostics != nil {
+		d.Diagnostics.Warn(d.Warnings(it))
+		defer d.Diagnostics.Flush()
+	}
+	return it.Close()
+}
@@ -0,0 +1,56 @@
+package core
+
+import "context"
+
+// LegacySession is the session interface from before WithContext took a
+// context.Context. Wrap implementations of it with AdaptLegacySession.
+type LegacySession interface {
+	Query(string, ...interface{}) LegacyQuery
+}
+
+// LegacyQuery is the query interface from before WithContext took a
+// context.Context.
+type LegacyQuery interface {
+	PageSize(int) LegacyQuery
+	PageState([]byte) LegacyQuery
+	WithContext(interface{}) LegacyQuery
+	Iter() CassandraIter
+}
+
+// AdaptLegacySession turns a LegacySession into a CassandraSession. Existing
+// implementations only need their method results changed from CassandraQuery
+// to LegacyQuery; new code should implement CassandraSession directly.
+func AdaptLegacySession(s LegacySession) CassandraSession {
+	return legacySession{s}
+}
+
+type legacySession struct {
+	LegacySession
+}
+
+func (s legacySession) Query(stmt string, values ...interface{}) CassandraQuery {
+	return &legacyQuery{s.LegacySession.Query(stmt, values...)}
+}
+
+type legacyQuery struct {
+	q LegacyQuery
+}
+
+func (q *legacyQuery) PageSize(n int) CassandraQuery {
+	q.q = q.q.PageSize(n)
+	return q
+}
+
+func (q *legacyQuery) PageState(b []byte) CassandraQuery {
+	q.q = q.q.PageState(b)
+	return q
+}
+
+func (q *legacyQuery) WithContext(ctx context.Context) CassandraQuery {
+	q.q = q.q.WithContext(ctx)
+	return q
+}
+
+func (q *legacyQuery) Iter() CassandraIter {
+	return q.q.Iter()
+}
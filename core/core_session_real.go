@@ -9,15 +9,22 @@ import (
 // RealSession wraps a *gocql.Session to implement CassandraSession.
 type RealSession struct {
 	*gocql.Session
+
+	// QueryObserver is the ClusterConfig.QueryObserver of the session, if any.
+	// Pages sampled by Options.Diagnostics observe their queries and keep
+	// calling it; the driver offers no way to read it back from the session.
+	QueryObserver gocql.QueryObserver
 }
 
 func (s *RealSession) Query(stmt string, values ...interface{}) CassandraQuery {
-	return &RealQuery{s.Session.Query(stmt, values...)}
+	return &RealQuery{Query: s.Session.Query(stmt, values...), Observer: s.QueryObserver}
 }
 
 // RealQuery wraps a *gocql.Query.
 type RealQuery struct {
 	*gocql.Query
+	Observer    gocql.QueryObserver // session observer chained by Diagnose
+	diagnostics *DiagnosticsCollector
 }
 
 func (q *RealQuery) PageSize(n int) CassandraQuery {
@@ -30,22 +37,93 @@ func (q *RealQuery) PageState(b []byte) CassandraQuery {
 	return q
 }
 
-func (q *RealQuery) WithContext(ctx interface{}) CassandraQuery {
-	if c, ok := ctx.(context.Context); ok {
-		q.Query = q.Query.WithContext(c)
-	}
+func (q *RealQuery) WithContext(ctx context.Context) CassandraQuery {
+	q.Query = q.Query.WithContext(ctx)
+	return q
+}
+
+// Configure applies the consistency levels, idempotency, driver retries and
+// timestamp setting of cfg.
+func (q *RealQuery) Configure(cfg QueryConfig) CassandraQuery {
+	q.driver().Configure(cfg)
+	return q
+}
+
+// Diagnose observes the driver requests of the query, passing them on to
+// q.Observer, traces them when trace is set and fills in d when the iterator is
+// closed.
+func (q *RealQuery) Diagnose(d *PageDiagnostics, trace bool) CassandraQuery {
+	q.diagnostics = q.driver().Diagnose(d, trace)
 	return q
 }
 
+// driver returns the setters of the wrapped query.
+func (q *RealQuery) driver() DriverQuery {
+	return DriverQuery{
+		Consistency:       func(level gocql.Consistency) { q.Query = q.Query.Consistency(level) },
+		SerialConsistency: func(level gocql.SerialConsistency) { q.Query = q.Query.SerialConsistency(level) },
+		Idempotent:        func() { q.Query = q.Query.Idempotent(true) },
+		Retries:           func(policy DriverRetry) { q.Query = q.Query.RetryPolicy(driverRetry(policy)) },
+		DefaultTimestamp:  func(enabled bool) { q.Query = q.Query.DefaultTimestamp(enabled) },
+		Observe: func(c *DiagnosticsCollector) {
+			q.Query = q.Query.Observer(&driverDiagnostics{DiagnosticsCollector: c, next: q.Observer})
+		},
+		Trace: func(c *DiagnosticsCollector) { q.Query = q.Query.Trace(c) },
+	}
+}
+
+// driverRetry is DriverRetry as a driver retry policy.
+type driverRetry DriverRetry
+
+func (n driverRetry) Attempt(q gocql.RetryableQuery) bool {
+	return DriverRetry(n).Retry(q.Attempts())
+}
+
+func (n driverRetry) GetRetryType(err error) gocql.RetryType {
+	if DriverRetry(n).SameHost(err) {
+		return gocql.Retry
+	}
+	return gocql.RetryNextHost
+}
+
 func (q *RealQuery) Iter() CassandraIter {
-	return &RealIter{q.Query.Iter()}
+	return &RealIter{Iter: q.Query.Iter(), driver: DriverIter{Diagnostics: q.diagnostics}}
 }
 
 // RealIter wraps a *gocql.Iter.
 type RealIter struct {
 	*gocql.Iter
+	driver DriverIter
 }
 
-func (i *RealIter) MapScan(m map[string]interface{}) bool { return i.Iter.MapScan(m) }
+func (i *RealIter) MapScan(m map[string]interface{}) bool { return i.driver.MapScan(i.Iter, m) }
 func (i *RealIter) PageState() []byte                     { return i.Iter.PageState() }
-func (i *RealIter) Close() error                          { return i.Iter.Close() }
+
+// Warnings returns the server warnings of the driver pages read so far.
+func (i *RealIter) Warnings() []string { return i.driver.Warnings(i.Iter) }
+
+func (i *RealIter) Close() error { return i.driver.Close(i.Iter) }
+
+// driverDiagnostics reports the driver's observations of one query to a
+// DiagnosticsCollector, passing them on to the session's observer.
+type driverDiagnostics struct {
+	*DiagnosticsCollector
+	next gocql.QueryObserver // observer the driver would have called
+}
+
+func (d *driverDiagnostics) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	coordinator := ""
+	if q.Host != nil {
+		coordinator = q.Host.ConnectAddressAndPort()
+	}
+	d.Request(coordinator)
+	if d.next != nil {
+		d.next.ObserveQuery(ctx, q)
+	}
+}
+
+var (
+	_ ConfigurableQuery = (*RealQuery)(nil)
+	_ DiagnosticQuery   = (*RealQuery)(nil)
+	_ WarningIter       = (*RealIter)(nil)
+)
@@ -0,0 +1,390 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CountMode selects how Count computes the total number of rows.
+type CountMode int
+
+const (
+	// CountAuto uses an estimate only where partitions are rows: unfiltered
+	// queries over the whole ring of a table known to have no clustering
+	// columns. Everything else is counted exactly.
+	CountAuto CountMode = iota
+	// CountExact runs SELECT COUNT(*) with the paginator's filters.
+	CountExact
+	// CountEstimate reads system.size_estimates; cheap but approximate, and it
+	// counts partitions rather than rows. With Options.TokenRange it is scaled
+	// down to the range's share of the ring.
+	CountEstimate
+)
+
+// String returns the mode name, suitable for API responses.
+func (m CountMode) String() string {
+	switch m {
+	case CountExact:
+		return "exact"
+	case CountEstimate:
+		return "estimate"
+	default:
+		return "auto"
+	}
+}
+
+// CountOptions configures Count.
+type CountOptions struct {
+	Mode CountMode
+
+	// Splits divides an exact count of an unfiltered table into this many
+	// token ranges (default 1). Requires partition keys from Options.Keys or Options.Schema.
+	Splits int
+	// Parallelism is the number of range queries run concurrently (default 1).
+	Parallelism int
+
+	// Shards further divides Options.TokenRange along a ScyllaDB node's shard
+	// layout. Each shard's ranges are counted in turn by one worker, and
+	// Parallelism bounds the shards counted at once (default: all of them).
+	// Every shard owns 2^IgnoreMSB interleaved slices of the ring, each counted
+	// with its own query, so the full ring would take 2^IgnoreMSB × Shards
+	// queries (32768 for 8 shards and the default ignore_msb of 12). Shards
+	// therefore requires Options.TokenRange, such as one vnode's range, and
+	// fails with ErrCountUnsupported without it; Splits only adds boundaries.
+	Shards *ShardLayout
+}
+
+// CountResult is the outcome of Count.
+type CountResult struct {
+	Count       int64
+	Mode        CountMode // mode actually used: CountExact or CountEstimate
+	Approximate bool      // true when Count is an estimate
+}
+
+// Count returns the number of rows matched by the paginator's query and filters.
+// Estimates come from system.size_estimates on the coordinator, scaled from the
+// token ranges it holds estimates for to the whole ring. They count partitions,
+// so CountAuto only picks them when every partition is one row.
+func (p *Paginator) Count(opts CountOptions) (CountResult, error) {
+	start := time.Now()
+
+	mode := opts.Mode
+	if mode == CountAuto {
+		mode = CountExact
+		if p.estimateFits() {
+			mode = CountEstimate
+		}
+	}
+
+	var result CountResult
+	var err error
+	if mode == CountEstimate {
+		result, err = p.estimate()
+		if err != nil && opts.Mode == CountAuto {
+			result, err = p.countExact(opts)
+		}
+	} else {
+		result, err = p.countExact(opts)
+	}
+	if err != nil {
+		return CountResult{}, err
+	}
+
+	p.Opts.log("count_completed", map[string]interface{}{
+		"count":       result.Count,
+		"mode":        result.Mode.String(),
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+
+	return result, nil
+}
+
+// estimateFits reports whether a partition estimate stands for the row count
+// of the query: it reads the whole ring of a table whose partitions hold one
+// row each, because the table has no clustering columns.
+func (p *Paginator) estimateFits() bool {
+	if len(p.Opts.Filters) > 0 || p.Opts.TokenRange != nil || strings.Contains(strings.ToLower(p.Query), "where") {
+		return false
+	}
+	keys := p.keys()
+	return keys != nil && len(keys.PartitionKeys) > 0 && len(keys.ClusteringKeys) == 0
+}
+
+var (
+	selectListPattern = regexp.MustCompile(`(?is)^\s*SELECT\s+.+?\s+FROM\s+`)
+	orderByPattern    = regexp.MustCompile(`(?is)\s+ORDER\s+BY\s+.*$`)
+	limitPattern      = regexp.MustCompile(`(?i)\bLIMIT\b`)
+)
+
+// countQuery turns the paginator's query into a SELECT COUNT(*). ORDER BY does
+// not change a count and is dropped; LIMIT would, so such queries are refused.
+func (p *Paginator) countQuery() (string, error) {
+	if limitPattern.MatchString(p.Query) {
+		return "", fmt.Errorf("%w: LIMIT in %q", ErrCountUnsupported, p.Query)
+	}
+	base := selectListPattern.ReplaceAllString(p.Query, "SELECT COUNT(*) FROM ")
+	return orderByPattern.ReplaceAllString(base, ""), nil
+}
+
+// countExact runs SELECT COUNT(*), optionally split across token ranges.
+func (p *Paginator) countExact(opts CountOptions) (CountResult, error) {
+	base, err := p.countQuery()
+	if err != nil {
+		return CountResult{}, err
+	}
+	if opts.Shards != nil && p.Opts.TokenRange == nil {
+		return CountResult{}, fmt.Errorf("%w: CountOptions.Shards needs Options.TokenRange, as every shard owns 2^%d slices of the ring", ErrCountUnsupported, opts.Shards.IgnoreMSB)
+	}
+
+	// Ranges counted by the same worker, one group per range or per shard
+	var groups [][]TokenRange
+	keys := p.keys()
+	if (opts.Splits > 1 || opts.Shards != nil) && keys != nil && len(keys.PartitionKeys) > 0 && !p.filtersPartitionKey(keys) {
+		ring := FullRing()
+		if p.Opts.TokenRange != nil {
+			ring = *p.Opts.TokenRange
+		}
+		ranges := ring.Split(opts.Splits)
+		if opts.Shards != nil && opts.Shards.Valid() {
+			groups = make([][]TokenRange, opts.Shards.Shards)
+			for _, r := range ranges {
+				for shard, sub := range opts.Shards.ShardRanges(r) {
+					groups[shard] = append(groups[shard], sub...)
+				}
+			}
+		} else {
+			for _, r := range ranges {
+				groups = append(groups, []TokenRange{r})
+			}
+		}
+	}
+
+	// Unsplit count: a single query
+	if len(groups) == 0 {
+		queryStr, bindValues, err := p.buildQuery(base, queryParts{})
+		if err != nil {
+			return CountResult{}, err
+		}
+		count, err := p.runCount(queryStr, bindValues)
+		if err != nil {
+			return CountResult{}, err
+		}
+		return CountResult{Count: count, Mode: CountExact}, nil
+	}
+
+	// Split count: one query per token range, summed
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+		if opts.Shards != nil {
+			parallelism = len(groups)
+		}
+	}
+
+	// Build every query first, so a bad one fails before any worker starts
+	type rangeQuery struct {
+		stmt   string
+		values []interface{}
+	}
+	batches := make([][]rangeQuery, len(groups))
+	for g, group := range groups {
+		batches[g] = make([]rangeQuery, len(group))
+		for i := range group {
+			queryStr, bindValues, err := p.buildQuery(base, queryParts{tokenRange: &group[i]})
+			if err != nil {
+				return CountResult{}, err
+			}
+			batches[g][i] = rangeQuery{queryStr, bindValues}
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		total    int64
+		firstErr error
+	)
+	sem := make(chan struct{}, parallelism)
+
+	for _, queries := range batches {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, q := range queries {
+				count, err := p.runCount(q.stmt, q.values)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				total += count
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return CountResult{}, firstErr
+	}
+	return CountResult{Count: total, Mode: CountExact}, nil
+}
+
+// runCount executes a COUNT query and sums the returned counts.
+func (p *Paginator) runCount(queryStr string, bindValues []interface{}) (int64, error) {
+	var total int64
+
+	// Each attempt waits for the rate limiter, reserving a page of rows, and is
+	// then charged the rows it counted
+	var limitErr error
+	attempts, err := p.retry(p.Opts.context(), queryStr, func() error {
+		if limitErr = p.throttle(p.Opts.context(), p.PageSize); limitErr != nil {
+			return limitErr
+		}
+		q := p.configure(p.Session.Query(queryStr, bindValues...).PageSize(p.PageSize))
+		q = q.WithContext(p.Opts.context())
+
+		iter := q.Iter()
+		total = 0
+		row := map[string]interface{}{}
+		for iter.MapScan(row) {
+			total += toInt64(row["count"])
+			row = map[string]interface{}{}
+		}
+		err := iter.Close()
+		if err == nil {
+			p.settle(p.PageSize, int(total))
+		}
+		return err
+	})
+
+	if limitErr != nil {
+		return 0, limitErr
+	}
+	if err != nil {
+		return 0, p.queryFailed(queryStr, err, attempts, nil)
+	}
+	return total, nil
+}
+
+// estimate sums partitions_count from system.size_estimates for the queried
+// table. The coordinator only holds estimates for its own token ranges, so the
+// sum is scaled up by the share of the ring they cover.
+func (p *Paginator) estimate() (CountResult, error) {
+	keyspace, table, ok := parseTableName(p.Query)
+	if !ok {
+		return CountResult{}, fmt.Errorf("%w: cannot find table name in query", ErrEstimateUnavailable)
+	}
+	if keyspace == "" {
+		if schema, err := p.Schema(); err == nil && schema != nil {
+			keyspace = schema.Keyspace
+		}
+	}
+	if keyspace == "" {
+		return CountResult{}, fmt.Errorf("%w: keyspace of %q is unknown", ErrEstimateUnavailable, table)
+	}
+
+	if err := p.throttle(p.Opts.context(), 0); err != nil {
+		return CountResult{}, err
+	}
+	q := p.Session.Query("SELECT range_start, range_end, partitions_count FROM system.size_estimates WHERE keyspace_name = ? AND table_name = ?", keyspace, table)
+	q = p.configure(q).WithContext(p.Opts.context())
+
+	iter := q.Iter()
+	var total int64
+	var covered float64 // share of the ring the ranges cover, 0 when unknown
+	ranges := 0
+	row := map[string]interface{}{}
+	for iter.MapScan(row) {
+		total += toInt64(row["partitions_count"])
+		if share, ok := ringShare(row["range_start"], row["range_end"]); ok && (ranges == 0 || covered > 0) {
+			covered += share
+		} else {
+			covered = 0
+		}
+		ranges++
+		row = map[string]interface{}{}
+	}
+	if err := iter.Close(); err != nil {
+		return CountResult{}, fmt.Errorf("%w: %v", ErrEstimateUnavailable, err)
+	}
+	if ranges == 0 {
+		return CountResult{}, fmt.Errorf("%w: no size estimates for %s.%s", ErrEstimateUnavailable, keyspace, table)
+	}
+
+	if covered > 0 && covered < 1 {
+		total = int64(math.Round(float64(total) / covered))
+	}
+	if r := p.Opts.TokenRange; r != nil {
+		total = int64(math.Round(float64(total) * r.share()))
+	}
+
+	return CountResult{Count: total, Mode: CountEstimate, Approximate: true}, nil
+}
+
+// ringShare returns the share of the token ring between two size_estimates
+// range bounds, which are Murmur3 tokens as text.
+func ringShare(start, end interface{}) (float64, bool) {
+	s, ok := start.(string)
+	if !ok {
+		return 0, false
+	}
+	e, ok := end.(string)
+	if !ok {
+		return 0, false
+	}
+	from, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	to, err := strconv.ParseInt(e, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if from == to {
+		// a single range covering the whole ring
+		return 1, true
+	}
+	return TokenRange{Start: from, End: to}.share(), true
+}
+
+// share returns the share of the token ring in (Start, End].
+func (r TokenRange) share() float64 {
+	// Unsigned subtraction also measures the range that wraps around the ring
+	return float64(uint64(r.End)-uint64(r.Start)) / math.Pow(2, 64)
+}
+
+// filtersPartitionKey reports whether any filter restricts a partition key column.
+func (p *Paginator) filtersPartitionKey(keys *TableKeys) bool {
+	for k := range p.Opts.Filters {
+		column, _ := parseFilterKey(k)
+		if containsColumn(keys.PartitionKeys, unquote(column)) {
+			return true
+		}
+	}
+	return false
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	}
+	return 0
+}
@@ -0,0 +1,189 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+func TestTokenRange_Split(t *testing.T) {
+	ranges := core.FullRing().Split(4)
+	if len(ranges) != 4 {
+		t.Fatalf("expected 4 ranges, got %d", len(ranges))
+	}
+	if ranges[0].Start != math.MinInt64 || ranges[3].End != math.MaxInt64 {
+		t.Errorf("ranges do not cover the ring: %v", ranges)
+	}
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].Start != ranges[i-1].End {
+			t.Errorf("gap between %v and %v", ranges[i-1], ranges[i])
+		}
+	}
+}
+
+// usersSession holds n users keyed by user_id, spread over the token ring.
+func usersSession(n int) *caspagetest.Session {
+	rows := numberedRows(n)
+	for i, row := range rows {
+		row["user_id"] = fmt.Sprintf("user-%d", i)
+	}
+	s := caspagetest.NewSession()
+	s.AddTable("users", rows)
+	return s
+}
+
+func TestPaginator_CountExact(t *testing.T) {
+	session := usersSession(40)
+	p := core.NewPaginator(session, "SELECT name, email FROM users", core.Options{
+		Keys: &core.TableKeys{PartitionKeys: []string{"user_id"}},
+	})
+
+	res, err := p.Count(core.CountOptions{Mode: core.CountExact, Splits: 4, Parallelism: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Count != 40 || res.Mode != core.CountExact || res.Approximate {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	ranged := 0
+	for _, q := range session.Queries() {
+		if !strings.HasPrefix(q.Statement, "SELECT COUNT(*) FROM users") {
+			t.Errorf("unexpected statement %q", q.Statement)
+		}
+		if strings.Contains(q.Statement, "token(user_id) > ? AND token(user_id) <= ?") {
+			ranged++
+		}
+	}
+	if ranged != 4 {
+		t.Errorf("expected 4 token range queries, got %d", ranged)
+	}
+}
+
+// estimatesSession holds size estimates for shop.orders and the orders table.
+func estimatesSession(estimates ...map[string]interface{}) *caspagetest.Session {
+	s := caspagetest.NewSession()
+	for _, e := range estimates {
+		e["keyspace_name"], e["table_name"] = "shop", "orders"
+	}
+	s.AddTable("system.size_estimates", estimates)
+
+	var orders []map[string]interface{}
+	for i := 0; i < 10; i++ {
+		status := "open"
+		if i >= 7 {
+			status = "closed"
+		}
+		orders = append(orders, map[string]interface{}{"id": i, "status": status})
+	}
+	s.AddTable("orders", orders)
+	return s
+}
+
+func TestPaginator_CountAuto(t *testing.T) {
+	session := estimatesSession(
+		map[string]interface{}{"partitions_count": int64(4000)},
+		map[string]interface{}{"partitions_count": int64(300)},
+	)
+
+	// Unfiltered query of a table without clustering columns: estimate
+	orderKeys := &core.TableKeys{PartitionKeys: []string{"id"}}
+	p := core.NewPaginator(session, "SELECT * FROM shop.orders", core.Options{Keys: orderKeys})
+	res, err := p.Count(core.CountOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Count != 4300 || res.Mode != core.CountEstimate || !res.Approximate {
+		t.Errorf("unexpected estimate: %+v", res)
+	}
+
+	// Partitions are not rows, or the table is unknown: exact
+	for name, opts := range map[string]core.Options{
+		"clustering columns": {Keys: &core.TableKeys{PartitionKeys: []string{"id"}, ClusteringKeys: []string{"line"}}},
+		"unknown keys":       {},
+	} {
+		res, err = core.NewPaginator(session, "SELECT * FROM shop.orders", opts).Count(core.CountOptions{})
+		if err != nil || res.Count != 10 || res.Mode != core.CountExact {
+			t.Errorf("%s: expected an exact count, got %+v (%v)", name, res, err)
+		}
+	}
+
+	// Token range: exact, and an explicit estimate covers only the range
+	r := core.FullRing().Split(4)[0]
+	p = core.NewPaginator(session, "SELECT * FROM shop.orders", core.Options{Keys: orderKeys, TokenRange: &r})
+	if res, err = p.Count(core.CountOptions{}); err != nil || res.Mode != core.CountExact {
+		t.Errorf("expected an exact count of the range, got %+v (%v)", res, err)
+	}
+	if res, err = p.Count(core.CountOptions{Mode: core.CountEstimate}); err != nil || res.Count != 1075 {
+		t.Errorf("expected a quarter of the estimate, got %+v (%v)", res, err)
+	}
+
+	// Filtered query: exact
+	p = core.NewPaginator(session, "SELECT * FROM shop.orders", core.Options{
+		Keys:    orderKeys,
+		Filters: map[string]interface{}{"status": "open"},
+	})
+	res, err = p.Count(core.CountOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Count != 7 || res.Mode != core.CountExact {
+		t.Errorf("unexpected exact count: %+v", res)
+	}
+
+	// Unknown keyspace: estimate unavailable, auto falls back to exact
+	p = core.NewPaginator(session, "SELECT * FROM orders", core.Options{Keys: orderKeys})
+	if _, err := p.Count(core.CountOptions{Mode: core.CountEstimate}); !errors.Is(err, core.ErrEstimateUnavailable) {
+		t.Errorf("expected ErrEstimateUnavailable, got %v", err)
+	}
+	res, err = p.Count(core.CountOptions{})
+	if err != nil || res.Count != 10 || res.Mode != core.CountExact {
+		t.Errorf("expected exact fallback, got %+v (%v)", res, err)
+	}
+}
+
+func TestPaginator_CountEstimateScalesToRing(t *testing.T) {
+	// Two ranges, each an eighth of the ring
+	session := estimatesSession(
+		map[string]interface{}{"range_start": "-9223372036854775808", "range_end": "-6917529027641081856", "partitions_count": int64(100)},
+		map[string]interface{}{"range_start": "0", "range_end": "2305843009213693952", "partitions_count": int64(100)},
+	)
+	p := core.NewPaginator(session, "SELECT * FROM shop.orders", core.Options{})
+
+	res, err := p.Count(core.CountOptions{Mode: core.CountEstimate})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Count != 800 || !res.Approximate {
+		t.Errorf("expected the estimate scaled to the whole ring, got %+v", res)
+	}
+}
+
+func TestPaginator_CountQuery(t *testing.T) {
+	session := caspagetest.NewSession()
+	session.AddTable("messages", []map[string]interface{}{
+		{"room": "a", "ts": 1}, {"room": "a", "ts": 2}, {"room": "b", "ts": 3}, {"room": "a", "ts": 4},
+	})
+	filters := map[string]interface{}{"room": "a"}
+
+	// ORDER BY does not change a count and is dropped
+	p := core.NewPaginator(session, "SELECT * FROM messages ORDER BY ts DESC", core.Options{Filters: filters})
+	res, err := p.Count(core.CountOptions{Mode: core.CountExact})
+	if err != nil || res.Count != 3 {
+		t.Fatalf("unexpected result %+v (%v)", res, err)
+	}
+	if q := session.Queries(); len(q) != 1 || q[0].Statement != "SELECT COUNT(*) FROM messages WHERE room = ?" {
+		t.Errorf("unexpected queries %+v", q)
+	}
+
+	// LIMIT would, so the count is refused
+	p = core.NewPaginator(session, "SELECT * FROM messages LIMIT 10", core.Options{Filters: filters})
+	if _, err := p.Count(core.CountOptions{Mode: core.CountExact}); !errors.Is(err, core.ErrCountUnsupported) {
+		t.Errorf("expected ErrCountUnsupported, got %v", err)
+	}
+}
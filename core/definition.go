@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// Definition is an immutable paginator definition: a session, a query and
+// Options fixed at construction. It is safe for concurrent use, so one
+// Definition can serve every request of a handler; each call supplies its
+// own context and may override the page size and add filters.
+//
+// Paginators derived from a Definition share its lazily loaded schema and
+// adaptive fetch size, which a Paginator built per request would not.
+type Definition struct {
+	base *Paginator
+}
+
+// CallOptions are per-call overrides of a Definition's options. The zero
+// value keeps the definition's settings.
+type CallOptions struct {
+	PageSize int                    // rows per page for this call (default: the definition's)
+	Filters  map[string]interface{} // added to the definition's filters, replacing those with the same key
+}
+
+// NewDefinition creates a Definition. The Filters and Columns of opts are
+// copied, so later changes by the caller do not affect it.
+func NewDefinition(session CassandraSession, query string, opts Options) *Definition {
+	opts.Filters = maps.Clone(opts.Filters)
+	opts.Columns = slices.Clone(opts.Columns)
+	return &Definition{base: NewPaginator(session, query, opts)}
+}
+
+// Query returns the definition's base query.
+func (d *Definition) Query() string {
+	return d.base.Query
+}
+
+// Paginator returns a Paginator for one call, bound to ctx and the overrides.
+// It belongs to the caller: changing its fields leaves the Definition as is.
+func (d *Definition) Paginator(ctx context.Context, call CallOptions) *Paginator {
+	opts := d.base.Opts
+	opts.Context = ctx
+	opts.Columns = slices.Clone(opts.Columns)
+	opts.Filters = maps.Clone(opts.Filters)
+	if len(call.Filters) > 0 {
+		if opts.Filters == nil {
+			opts.Filters = make(map[string]interface{}, len(call.Filters))
+		}
+		maps.Copy(opts.Filters, call.Filters)
+	}
+	opts.PageSize = d.base.PageSize
+	if call.PageSize > 0 {
+		opts.PageSize = call.PageSize
+	}
+
+	p := NewPaginator(d.base.Session, d.base.Query, opts)
+	p.parent = d.base
+	return p
+}
+
+// FetchPage is Paginator(ctx, call).FetchPage(ctx, token).
+func (d *Definition) FetchPage(ctx context.Context, token string, call CallOptions) (Page[map[string]interface{}], error) {
+	return d.Paginator(ctx, call).FetchPage(ctx, token)
+}
+
+// NextWithToken is Paginator(ctx, call).NextWithTokenContext(ctx, token).
+func (d *Definition) NextWithToken(ctx context.Context, token string, call CallOptions) ([]map[string]interface{}, string, error) {
+	return d.Paginator(ctx, call).NextWithTokenContext(ctx, token)
+}
@@ -0,0 +1,166 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// countingLoader counts schema loads.
+type countingLoader struct {
+	schema core.StaticSchema
+	loads  atomic.Int32
+}
+
+func (l *countingLoader) LoadSchema(keyspace, table string) (*core.TableSchema, error) {
+	l.loads.Add(1)
+	return l.schema.LoadSchema(keyspace, table)
+}
+
+// groupedRows builds n rows spread over four partitions.
+func groupedRows(n int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"grp": i % 4, "id": i}
+	}
+	return rows
+}
+
+func TestDefinition_ConcurrentCalls(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", groupedRows(60))
+	loader := &countingLoader{schema: core.StaticSchema{"users": {
+		Table:     "users",
+		TableKeys: core.TableKeys{PartitionKeys: []string{"grp"}, ClusteringKeys: []string{"id"}},
+		Columns:   map[string]string{"grp": "int", "id": "int"},
+	}}}
+	def := core.NewDefinition(s, "SELECT * FROM users", core.Options{
+		PageSize:  7,
+		Schema:    loader,
+		Adaptive:  &core.AdaptiveFetch{MinFetchSize: 2, MaxFetchSize: 16},
+		LookAhead: true,
+	})
+
+	// Every goroutine pages through its own partition with its own page size,
+	// through the same Definition
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for w := 0; w < 32; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			grp, pageSize := w%4, 1+w%6
+			call := core.CallOptions{PageSize: pageSize, Filters: map[string]interface{}{"grp": grp}}
+
+			var got []int
+			token := ""
+			for {
+				page, err := def.FetchPage(context.Background(), token, call)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(page.Rows) > pageSize {
+					errs <- fmt.Errorf("worker %d: page of %d rows, want at most %d", w, len(page.Rows), pageSize)
+					return
+				}
+				got = append(got, ids(page.Rows)...)
+				if !page.HasMore {
+					break
+				}
+				token = page.NextToken
+			}
+
+			var want []int
+			for i := grp; i < 60; i += 4 {
+				want = append(want, i)
+			}
+			if !reflect.DeepEqual(got, want) {
+				errs <- fmt.Errorf("worker %d: got %v, want %v", w, got, want)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if n := loader.loads.Load(); n != 1 {
+		t.Errorf("expected the schema to be loaded once, got %d loads", n)
+	}
+}
+
+func TestDefinition_Isolation(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", groupedRows(12))
+	filters := map[string]interface{}{"grp": 1}
+	def := core.NewDefinition(s, "SELECT * FROM users", core.Options{PageSize: 5, Filters: filters})
+
+	// Changing the caller's map or a derived paginator leaves the definition as is
+	filters["grp"] = 2
+	p := def.Paginator(context.Background(), core.CallOptions{PageSize: 2})
+	p.PageSize = 100
+	p.Opts.Filters["grp"] = 3
+
+	rows, _, err := def.NextWithToken(context.Background(), "", core.CallOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(rows), []int{1, 5, 9}) {
+		t.Errorf("unexpected rows %v", ids(rows))
+	}
+
+	// Call filters replace the definition's filter on the same column
+	rows, _, err = def.NextWithToken(context.Background(), "", core.CallOptions{Filters: map[string]interface{}{"grp": 2}})
+	if err != nil || !reflect.DeepEqual(ids(rows), []int{2, 6, 10}) {
+		t.Errorf("unexpected rows %v (%v)", ids(rows), err)
+	}
+}
+
+func TestDefinition_PerCallContext(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", groupedRows(12))
+	def := core.NewDefinition(s, "SELECT * FROM users", core.Options{PageSize: 5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := def.FetchPage(ctx, "", core.CallOptions{}); err == nil {
+		t.Error("expected the cancelled call to fail")
+	}
+
+	// The stateful API of a derived paginator uses the call's context too
+	if _, _, err := def.Paginator(ctx, core.CallOptions{}).Next(); err == nil {
+		t.Error("expected Next to use the call's context")
+	}
+	if _, err := def.FetchPage(context.Background(), "", core.CallOptions{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDefinition_FetchSizeWithinCallBounds(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", groupedRows(60))
+	def := core.NewDefinition(s, "SELECT * FROM users", core.Options{PageSize: 40, Adaptive: &core.AdaptiveFetch{}})
+	ctx := context.Background()
+
+	// The first call sets the shared fetch size from its page size of 40; later
+	// calls keep within the default bounds of their own page size
+	for _, pageSize := range []int{40, 4, 40, 4} {
+		before := len(s.Queries())
+		if _, err := def.FetchPage(ctx, "", core.CallOptions{PageSize: pageSize}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, q := range s.Queries()[before:] {
+			if q.PageSize > pageSize || q.PageSize < max(pageSize/10, 1) {
+				t.Errorf("page size %d: fetch size %d outside the call's bounds", pageSize, q.PageSize)
+			}
+		}
+	}
+}
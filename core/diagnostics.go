@@ -0,0 +1,183 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Diagnostics configures per-page diagnostics (Options.Diagnostics). Sampled
+// pages collect the coordinator, attempts, latency and server warnings of their
+// queries and deliver them to the Logger ("page_diagnostics"), to collectors
+// implementing DiagnosticsObserver and in PageInfo.
+type Diagnostics struct {
+	SampleRate float64 // fraction of pages diagnosed, in (0, 1] (default: 1, every page)
+	Trace      bool    // enable driver tracing on sampled pages; costly on the cluster
+}
+
+// PageDiagnostics is what was observed while fetching a page.
+type PageDiagnostics struct {
+	Coordinator string        // host that served the last driver request
+	Attempts    int           // paginator attempts, more than 1 with Options.Retry
+	Latency     time.Duration // time to fetch the page, including retries
+	Requests    int           // driver requests, one per driver page and driver retry
+	Warnings    []string      // server warnings, such as tombstone thresholds
+	TraceID     string        // hex trace session id, with Diagnostics.Trace
+}
+
+// TombstoneWarnings returns the warnings about tombstones read for the page.
+func (d *PageDiagnostics) TombstoneWarnings() []string {
+	var out []string
+	for _, w := range d.Warnings {
+		if strings.Contains(strings.ToLower(w), "tombstone") {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// DiagnosticQuery is an optional extension of CassandraQuery. Queries
+// implementing it report what the driver observed for sampled pages; other
+// queries only get attempts and latency.
+type DiagnosticQuery interface {
+	// Diagnose fills in the Coordinator, Requests, Warnings and TraceID of d
+	// by the time the query's iterator is closed, tracing when trace is set.
+	Diagnose(d *PageDiagnostics, trace bool) CassandraQuery
+}
+
+// WarningIter is an optional extension of CassandraIter. Iterators
+// implementing it report the server warnings of every page in
+// PageInfo.Warnings, whether or not the page was sampled.
+type WarningIter interface {
+	// Warnings returns the warnings of the driver pages read so far, once each.
+	Warnings() []string
+}
+
+// DiagnosticsCollector gathers what a driver reports for one query and adds it
+// to the page's PageDiagnostics on Flush. It holds everything that does not
+// depend on the driver, so an adapter implementing DiagnosticQuery only turns
+// its driver's observer and tracer callbacks into Request and Trace calls.
+// It is safe for concurrent use, as drivers report from their own goroutines.
+type DiagnosticsCollector struct {
+	target *PageDiagnostics
+
+	mu          sync.Mutex
+	coordinator string
+	requests    int
+	warnings    []string
+	traceID     string
+}
+
+// NewDiagnosticsCollector returns a collector that flushes into d.
+func NewDiagnosticsCollector(d *PageDiagnostics) *DiagnosticsCollector {
+	return &DiagnosticsCollector{target: d}
+}
+
+// Request records one request sent to the driver, and the coordinator that
+// served it when known.
+func (c *DiagnosticsCollector) Request(coordinator string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if coordinator != "" {
+		c.coordinator = coordinator
+	}
+}
+
+// Trace records the id of the query trace; it implements the Tracer of both
+// gocql drivers.
+func (c *DiagnosticsCollector) Trace(traceID []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.traceID = hex.EncodeToString(traceID)
+}
+
+// Warn records server warnings, once each.
+func (c *DiagnosticsCollector) Warn(warnings []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = appendNew(c.warnings, warnings)
+}
+
+// Flush adds the collected diagnostics to the page's, across retried attempts.
+func (c *DiagnosticsCollector) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.target
+	if c.coordinator != "" {
+		t.Coordinator = c.coordinator
+	}
+	t.Requests += c.requests
+	t.Warnings = appendNew(t.Warnings, c.warnings)
+	if c.traceID != "" {
+		t.TraceID = c.traceID
+	}
+}
+
+// appendNew appends the values list does not hold yet.
+func appendNew(list, values []string) []string {
+	for _, v := range values {
+		if !contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// DiagnosticsObserver is an optional extension of MetricsCollector. Collectors
+// implementing it receive the diagnostics of every sampled page.
+type DiagnosticsObserver interface {
+	ObservePageDiagnostics(d PageDiagnostics)
+}
+
+// sampleDiagnostics returns the diagnostics to fill for the next page, or nil
+// when the page is not sampled.
+func (p *Paginator) sampleDiagnostics() *PageDiagnostics {
+	cfg := p.Opts.Diagnostics
+	if cfg == nil {
+		return nil
+	}
+	if cfg.SampleRate > 0 && cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate {
+		return nil
+	}
+	return &PageDiagnostics{}
+}
+
+// diagnose attaches d to queries implementing DiagnosticQuery when the page is
+// sampled, tracing them when Diagnostics.Trace is set.
+func (p *Paginator) diagnose(q CassandraQuery, d *PageDiagnostics) CassandraQuery {
+	if d == nil {
+		return q
+	}
+	if dq, ok := q.(DiagnosticQuery); ok {
+		return dq.Diagnose(d, p.Opts.Diagnostics.Trace)
+	}
+	return q
+}
+
+// observeDiagnostics delivers the diagnostics of a page to the logger and metrics.
+func (p *Paginator) observeDiagnostics(queryStr string, d *PageDiagnostics) {
+	data := map[string]interface{}{
+		"query":      queryStr,
+		"attempts":   d.Attempts,
+		"latency_ms": d.Latency.Milliseconds(),
+		"requests":   d.Requests,
+	}
+	if d.Coordinator != "" {
+		data["coordinator"] = d.Coordinator
+	}
+	if len(d.Warnings) > 0 {
+		data["warnings"] = d.Warnings
+		data["tombstone_warnings"] = len(d.TombstoneWarnings())
+	}
+	if d.TraceID != "" {
+		data["trace_id"] = d.TraceID
+	}
+	p.Opts.log("page_diagnostics", data)
+
+	if o, ok := p.Opts.Metrics.(DiagnosticsObserver); ok {
+		o.ObservePageDiagnostics(*d)
+	}
+}
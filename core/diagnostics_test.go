@@ -0,0 +1,141 @@
+package core_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// diagnosticsRecorder records diagnostics reported to the optional DiagnosticsObserver.
+type diagnosticsRecorder struct {
+	mu    sync.Mutex
+	pages []core.PageDiagnostics
+}
+
+func (r *diagnosticsRecorder) ObservePageFetch(int, time.Duration) {}
+func (r *diagnosticsRecorder) ObserveError(error)                  {}
+
+func (r *diagnosticsRecorder) ObservePageDiagnostics(d core.PageDiagnostics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pages = append(r.pages, d)
+}
+
+func TestDiagnostics_CollectedForSampledPages(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(12))
+	s.InjectWarning("FROM users", "Read 5 live rows and 3000 tombstone cells")
+	s.InjectWarning("FROM users", "Aggregation query used without partition key")
+	s.InjectError("FROM users", caspagetest.ErrReadTimeout, 1)
+
+	metrics := &diagnosticsRecorder{}
+	var events []map[string]interface{}
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{
+		PageSize:    5,
+		Adaptive:    &core.AdaptiveFetch{MinFetchSize: 2, MaxFetchSize: 2},
+		Retry:       &core.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		Diagnostics: &core.Diagnostics{Trace: true},
+		Metrics:     metrics,
+		Logger: func(event string, data map[string]interface{}) {
+			if event == "page_diagnostics" {
+				events = append(events, data)
+			}
+		},
+	})
+
+	rows, info, err := p.NextWithTokenInfo(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 5 || info.NextToken == "" || info.Attempts != 2 || info.Duration <= 0 {
+		t.Fatalf("unexpected page: %d rows, info %+v", len(rows), info)
+	}
+
+	// Both attempts are counted: one failed request, then three driver pages of 2 rows
+	d := info.Diagnostics
+	if d == nil {
+		t.Fatal("expected diagnostics")
+	}
+	if d.Coordinator != "caspagetest" || d.Attempts != 2 || d.Requests != 4 || d.TraceID == "" {
+		t.Errorf("unexpected diagnostics %+v", d)
+	}
+	if len(d.Warnings) != 2 || len(d.TombstoneWarnings()) != 1 {
+		t.Errorf("unexpected warnings %v", d.Warnings)
+	}
+
+	if len(metrics.pages) != 1 || metrics.pages[0].Requests != 4 {
+		t.Errorf("expected the diagnostics in the metrics, got %+v", metrics.pages)
+	}
+	if len(events) != 1 || events[0]["coordinator"] != "caspagetest" || events[0]["tombstone_warnings"] != 1 {
+		t.Errorf("unexpected log events %v", events)
+	}
+}
+
+func TestDiagnostics_Sampling(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(50))
+
+	// Without Options.Diagnostics pages carry only attempts and latency
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{PageSize: 5})
+	_, info, err := p.NextWithTokenInfo(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Diagnostics != nil || info.Attempts != 1 {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	// A tiny sample rate leaves (almost) every page out
+	p = core.NewPaginator(s, "SELECT * FROM users", core.Options{
+		PageSize:    5,
+		Diagnostics: &core.Diagnostics{SampleRate: 1e-9},
+	})
+	it := p.Pages("")
+	defer it.Close()
+	for it.Next() {
+		if it.Info().Diagnostics != nil {
+			t.Errorf("expected no sampled page, got %+v", it.Info().Diagnostics)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDiagnostics_SessionWithoutSupport(t *testing.T) {
+	p := core.NewPaginator(simpleSession{newTableSession("users", numberedRows(3))}, "SELECT * FROM users", core.Options{
+		PageSize:    5,
+		Diagnostics: &core.Diagnostics{},
+	})
+	_, info, err := p.NextWithTokenInfo(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := info.Diagnostics; d == nil || d.Attempts != 1 || d.Coordinator != "" {
+		t.Errorf("expected attempts without driver diagnostics, got %+v", d)
+	}
+}
+
+func TestDiagnosticsCollector(t *testing.T) {
+	d := &core.PageDiagnostics{}
+
+	// Two attempts of one page, each flushed when its iterator closes
+	for attempt := 0; attempt < 2; attempt++ {
+		c := core.NewDiagnosticsCollector(d)
+		c.Request("10.0.0.1:9042")
+		c.Request("")
+		c.Warn([]string{"tombstones", "tombstones"})
+		c.Trace([]byte{0xab, 0xcd})
+		c.Flush()
+	}
+
+	want := core.PageDiagnostics{Coordinator: "10.0.0.1:9042", Requests: 4, Warnings: []string{"tombstones"}, TraceID: "abcd"}
+	if !reflect.DeepEqual(*d, want) {
+		t.Errorf("expected %+v, got %+v", want, *d)
+	}
+}
@@ -1,9 +1,153 @@
 package core
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/gocql/gocql"
+)
 
 var (
 	ErrInvalidToken = errors.New("invalid page token")
 	ErrNoPrevToken  = errors.New("no previous token found")
 	ErrQueryFailed  = errors.New("failed to execute Cassandra query")
+	ErrPageTimeout  = errors.New("page fetch timed out")
+
+	ErrFilteringRequired   = errors.New("query requires ALLOW FILTERING")
+	ErrTableNotFound       = errors.New("table not found in schema")
+	ErrUnknownColumn       = errors.New("unknown column")
+	ErrPageOutOfRange      = errors.New("page out of range")
+	ErrPageWalkLimit       = errors.New("page is too far to walk to")
+	ErrEstimateUnavailable = errors.New("size estimate unavailable")
+	ErrKeysetUnavailable   = errors.New("keyset pagination unavailable")
+	ErrCountUnsupported    = errors.New("query cannot be counted")
+
+	ErrTokenRangeUnavailable = errors.New("token range restriction unavailable")
+)
+
+// ErrorKind classifies the cause of a QueryError.
+type ErrorKind int
+
+const (
+	// KindUnknown is any failure not covered by the other kinds.
+	KindUnknown ErrorKind = iota
+	// KindTimeout covers read timeouts, unanswered requests and expired deadlines.
+	KindTimeout
+	// KindUnavailable covers missing replicas and overloaded, bootstrapping or unreachable nodes.
+	KindUnavailable
+	// KindInvalidQuery covers syntax errors and queries the cluster rejects as invalid.
+	KindInvalidQuery
+	// KindUnauthorized covers authentication and permission failures.
+	KindUnauthorized
+	// KindCanceled means the context was cancelled.
+	KindCanceled
 )
+
+// String returns the kind name, suitable for logs and metric labels.
+func (k ErrorKind) String() string {
+	switch k {
+	case KindTimeout:
+		return "timeout"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInvalidQuery:
+		return "invalid_query"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// QueryError is returned when a Cassandra query fails. It wraps the driver
+// error, so errors.As works for driver error types, and errors.Is(err, ErrQueryFailed)
+// holds for every QueryError.
+type QueryError struct {
+	Kind     ErrorKind
+	Query    string
+	PageSize int
+	Attempts int // attempts made, more than 1 with Options.Retry
+	Err      error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%v (%s): %v", ErrQueryFailed, e.Kind, e.Err)
+}
+
+// Unwrap returns the driver error.
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrQueryFailed.
+func (e *QueryError) Is(target error) bool {
+	return target == ErrQueryFailed
+}
+
+// Cassandra error codes by kind.
+var errorKinds = map[int]ErrorKind{
+	gocql.ErrCodeReadTimeout:   KindTimeout,
+	gocql.ErrCodeWriteTimeout:  KindTimeout,
+	gocql.ErrCodeUnavailable:   KindUnavailable,
+	gocql.ErrCodeOverloaded:    KindUnavailable,
+	gocql.ErrCodeBootstrapping: KindUnavailable,
+	gocql.ErrCodeSyntax:        KindInvalidQuery,
+	gocql.ErrCodeInvalid:       KindInvalidQuery,
+	gocql.ErrCodeConfig:        KindInvalidQuery,
+	gocql.ErrCodeUnauthorized:  KindUnauthorized,
+	gocql.ErrCodeCredentials:   KindUnauthorized,
+}
+
+// classifyError returns the kind of a driver error.
+func classifyError(err error) ErrorKind {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, gocql.ErrTimeoutNoResponse):
+		return KindTimeout
+	case errors.Is(err, gocql.ErrNoConnections), errors.Is(err, gocql.ErrConnectionClosed):
+		return KindUnavailable
+	}
+
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return errorKinds[coded.Code()]
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return KindTimeout
+	}
+	return KindUnknown
+}
+
+// queryFailed wraps a driver error into a QueryError, logs it and records the metric.
+// Metrics receive the ErrQueryFailed sentinel so collectors keep a bounded label set.
+func (p *Paginator) queryFailed(queryStr string, err error, attempts int, data map[string]interface{}) *QueryError {
+	qe := &QueryError{
+		Kind:     classifyError(err),
+		Query:    queryStr,
+		PageSize: p.PageSize,
+		Attempts: attempts,
+		Err:      err,
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["query"] = queryStr
+	data["error"] = err.Error()
+	data["kind"] = qe.Kind.String()
+	data["filters"] = p.Opts.Filters
+	data["attempts"] = attempts
+	p.Opts.log("query_failed", data)
+
+	if p.Opts.Metrics != nil {
+		p.Opts.Metrics.ObserveError(ErrQueryFailed)
+	}
+	return qe
+}
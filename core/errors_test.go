@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/gocql/gocql"
+)
+
+func TestQueryError_WrapsAndClassifiesDriverErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		kind core.ErrorKind
+	}{
+		{codedError{gocql.ErrCodeReadTimeout}, core.KindTimeout},
+		{gocql.ErrTimeoutNoResponse, core.KindTimeout},
+		{context.DeadlineExceeded, core.KindTimeout},
+		{codedError{gocql.ErrCodeUnavailable}, core.KindUnavailable},
+		{codedError{gocql.ErrCodeOverloaded}, core.KindUnavailable},
+		{gocql.ErrNoConnections, core.KindUnavailable},
+		{codedError{gocql.ErrCodeSyntax}, core.KindInvalidQuery},
+		{codedError{gocql.ErrCodeInvalid}, core.KindInvalidQuery},
+		{codedError{gocql.ErrCodeUnauthorized}, core.KindUnauthorized},
+		{codedError{gocql.ErrCodeCredentials}, core.KindUnauthorized},
+		{context.Canceled, core.KindCanceled},
+		{errors.New("boom"), core.KindUnknown},
+	}
+
+	for _, c := range cases {
+		session := flakySession(3, 1, c.err)
+		p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 7})
+
+		_, _, err := p.Next()
+		if !errors.Is(err, core.ErrQueryFailed) {
+			t.Fatalf("%v: expected errors.Is ErrQueryFailed, got %v", c.err, err)
+		}
+		var qe *core.QueryError
+		if !errors.As(err, &qe) {
+			t.Fatalf("%v: expected a *QueryError, got %T", c.err, err)
+		}
+		if qe.Kind != c.kind {
+			t.Errorf("%v: kind %s, want %s", c.err, qe.Kind, c.kind)
+		}
+		if qe.Query != "SELECT * FROM users" || qe.PageSize != 7 || qe.Attempts != 1 {
+			t.Errorf("%v: unexpected details %+v", c.err, qe)
+		}
+		if !errors.Is(err, c.err) {
+			t.Errorf("%v: driver error not reachable through Unwrap", c.err)
+		}
+	}
+}
+
+func TestQueryError_DriverTypesAndAttempts(t *testing.T) {
+	session := flakySession(3, 5, codedError{gocql.ErrCodeReadTimeout})
+	var logged map[string]interface{}
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		Retry: &core.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		Logger: func(event string, data map[string]interface{}) {
+			if event == "query_failed" {
+				logged = data
+			}
+		},
+	})
+
+	_, _, err := p.Next()
+
+	var coded codedError
+	if !errors.As(err, &coded) || coded.Code() != gocql.ErrCodeReadTimeout {
+		t.Fatalf("expected the driver error type through errors.As, got %v", err)
+	}
+	var qe *core.QueryError
+	if !errors.As(err, &qe) || qe.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %+v", qe)
+	}
+	if !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected the kind in the message, got %q", err.Error())
+	}
+	if logged["kind"] != "timeout" || logged["attempts"] != 2 {
+		t.Errorf("unexpected query_failed event: %v", logged)
+	}
+}
+
+func TestPrevious_ReturnsSentinels(t *testing.T) {
+	p := core.NewPaginator(newTableSession("users", numberedRows(12)), "SELECT * FROM users", core.Options{PageSize: 5})
+
+	_, token, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := p.Previous(token); !errors.Is(err, core.ErrNoPrevToken) {
+		t.Errorf("expected ErrNoPrevToken on the first page, got %v", err)
+	}
+	if _, _, err := p.Previous("%%%"); !errors.Is(err, core.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+
+	u := core.NewUnionPaginator([]*core.Paginator{p}, core.UnionConcat, core.MergeOrder{}, core.Options{PageSize: 5})
+	_, token, err = u.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := u.Previous(token); !errors.Is(err, core.ErrNoPrevToken) {
+		t.Errorf("expected ErrNoPrevToken from the union, got %v", err)
+	}
+}
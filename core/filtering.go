@@ -0,0 +1,157 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FilteringPolicy controls how the paginator deals with ALLOW FILTERING.
+type FilteringPolicy int
+
+const (
+	// FilteringManual leaves the query untouched (default). Callers add
+	// ALLOW FILTERING to the base query themselves when they need it.
+	FilteringManual FilteringPolicy = iota
+
+	// FilteringAuto appends ALLOW FILTERING only when the filters cannot be
+	// served by the primary key or a secondary index.
+	FilteringAuto
+
+	// FilteringGuard refuses any query that requires ALLOW FILTERING, including
+	// base queries that already contain it. Useful to keep full-cluster scans
+	// out of production. It needs the table keys (Options.Keys or
+	// Options.Schema) to check filters, and refuses filtered queries without them.
+	FilteringGuard
+)
+
+// TableKeys describes the primary key and indexed columns of the queried table.
+// It can be set by hand through Options.Keys or loaded through Options.Schema.
+type TableKeys struct {
+	PartitionKeys  []string
+	ClusteringKeys []string
+	IndexedColumns []string
+
+	// ClusteringOrder maps clustering columns to "ASC" or "DESC" (default ASC).
+	ClusteringOrder map[string]string
+}
+
+// requiresFiltering reports whether Cassandra would reject the filters without
+// ALLOW FILTERING, together with a human-readable reason.
+// Only the filters are analysed; conditions written directly into the base query are not.
+// Column names are compared as CQL does: quoted names exactly, others lowercased.
+func requiresFiltering(keys *TableKeys, filters map[string]interface{}) (bool, string) {
+	if keys == nil || len(filters) == 0 {
+		return false, ""
+	}
+
+	ops := make(map[string]string, len(filters))
+	for k := range filters {
+		column, operator := parseFilterKey(k)
+		ops[unquote(column)] = operator
+	}
+
+	// 1️⃣ Every filtered column must be part of the primary key or indexed
+	indexed := 0
+	for column, operator := range ops {
+		switch {
+		case containsColumn(keys.PartitionKeys, column), containsColumn(keys.ClusteringKeys, column):
+		case containsColumn(keys.IndexedColumns, column):
+			if operator != "=" {
+				return true, fmt.Sprintf("range restriction on indexed column %q", column)
+			}
+			indexed++
+		default:
+			return true, fmt.Sprintf("column %q is neither a key nor indexed", column)
+		}
+	}
+	if indexed > 1 {
+		return true, "more than one indexed column restricted"
+	}
+
+	// 2️⃣ Partition key must be fully restricted by = or IN (or not at all)
+	restrictedPK := 0
+	for _, column := range keys.PartitionKeys {
+		operator, ok := ops[unquote(column)]
+		if !ok {
+			continue
+		}
+		if operator != "=" && operator != "IN" {
+			return true, fmt.Sprintf("range restriction on partition key %q", column)
+		}
+		restrictedPK++
+	}
+	fullPK := len(keys.PartitionKeys) > 0 && restrictedPK == len(keys.PartitionKeys)
+	if restrictedPK > 0 && !fullPK && indexed == 0 {
+		return true, "partition key is only partially restricted"
+	}
+
+	// 3️⃣ Clustering columns must be restricted in order, with only the last one as a range
+	previousRestricted, previousRange := true, false
+	for _, column := range keys.ClusteringKeys {
+		operator, ok := ops[unquote(column)]
+		if !ok {
+			previousRestricted = false
+			continue
+		}
+		if !fullPK && indexed == 0 {
+			return true, fmt.Sprintf("clustering column %q restricted without the full partition key", column)
+		}
+		if !previousRestricted || previousRange {
+			return true, fmt.Sprintf("clustering column %q restricted out of order", column)
+		}
+		previousRange = operator != "=" && operator != "IN"
+	}
+
+	return false, ""
+}
+
+// applyFilteringPolicy appends or refuses ALLOW FILTERING according to Opts.Filtering.
+func (p *Paginator) applyFilteringPolicy(queryStr string) (string, error) {
+	if p.Opts.Filtering == FilteringManual {
+		return queryStr, nil
+	}
+
+	explicit := strings.Contains(strings.ToUpper(queryStr), "ALLOW FILTERING")
+	keys := p.keys()
+	needed, reason := requiresFiltering(keys, p.Opts.Filters)
+
+	switch p.Opts.Filtering {
+	case FilteringAuto:
+		if needed && !explicit {
+			queryStr += " ALLOW FILTERING"
+		}
+	case FilteringGuard:
+		if explicit {
+			return "", fmt.Errorf("%w: base query contains ALLOW FILTERING", ErrFilteringRequired)
+		}
+		// Without the keys nothing proves the filters safe, so fail closed
+		if keys == nil && len(p.Opts.Filters) > 0 {
+			return "", fmt.Errorf("%w: key metadata is required to check the filters (set Options.Keys or Options.Schema)", ErrFilteringRequired)
+		}
+		if needed {
+			return "", fmt.Errorf("%w: %s", ErrFilteringRequired, reason)
+		}
+	}
+
+	return queryStr, nil
+}
+
+// containsColumn reports whether the normalised column name is in list.
+func containsColumn(list []string, column string) bool {
+	for _, v := range list {
+		if unquote(v) == column {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether s is in list.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
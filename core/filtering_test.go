@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseFilterKey(t *testing.T) {
+	cases := map[string][2]string{
+		"age >=":    {"age", ">="},
+		"age>":      {"age", ">"},
+		"region IN": {"region", "IN"},
+		"region in": {"region", "IN"},
+		"login":     {"login", "="},
+		" status ":  {"status", "="},
+	}
+
+	for key, want := range cases {
+		column, operator := parseFilterKey(key)
+		if column != want[0] || operator != want[1] {
+			t.Errorf("parseFilterKey(%q) = %q, %q; want %q, %q", key, column, operator, want[0], want[1])
+		}
+	}
+}
+
+func TestRequiresFiltering(t *testing.T) {
+	keys := &TableKeys{
+		PartitionKeys:  []string{"device_id", "day"},
+		ClusteringKeys: []string{"ts", "seq"},
+		IndexedColumns: []string{"status"},
+	}
+
+	cases := []struct {
+		name    string
+		filters map[string]interface{}
+		want    bool
+	}{
+		{"full partition key", map[string]interface{}{"device_id": "d1", "day": "2024-01-01"}, false},
+		{"partition key with IN", map[string]interface{}{"device_id IN": []string{"a", "b"}, "day": "x"}, false},
+		{"clustering range", map[string]interface{}{"device_id": "d1", "day": "x", "ts >": 10}, false},
+		{"single index", map[string]interface{}{"status": "active"}, false},
+		{"regular column", map[string]interface{}{"device_id": "d1", "day": "x", "payload": "y"}, true},
+		{"partial partition key", map[string]interface{}{"device_id": "d1"}, true},
+		{"clustering without partition", map[string]interface{}{"ts >": 10}, true},
+		{"clustering out of order", map[string]interface{}{"device_id": "d1", "day": "x", "seq": 1}, true},
+		{"clustering after range", map[string]interface{}{"device_id": "d1", "day": "x", "ts >": 1, "seq": 2}, true},
+		{"index range", map[string]interface{}{"status >": "a"}, true},
+		{"unquoted mixed case", map[string]interface{}{"Device_ID": "d1", `"day"`: "x", "TS >": 10}, false},
+		{"quoted mixed case", map[string]interface{}{`"Device_ID"`: "d1", "day": "x"}, true},
+	}
+
+	for _, tc := range cases {
+		got, reason := requiresFiltering(keys, tc.filters)
+		if got != tc.want {
+			t.Errorf("%s: requiresFiltering = %v (%s), want %v", tc.name, got, reason, tc.want)
+		}
+	}
+
+	if got, _ := requiresFiltering(nil, map[string]interface{}{"payload": 1}); got {
+		t.Error("expected no filtering decision without key metadata")
+	}
+}
+
+func TestApplyFilteringPolicy(t *testing.T) {
+	keys := &TableKeys{PartitionKeys: []string{"user_id"}}
+	filters := map[string]interface{}{"age >": 30}
+
+	auto := &Paginator{Opts: Options{Keys: keys, Filters: filters, Filtering: FilteringAuto}}
+	q, err := auto.applyFilteringPolicy("SELECT * FROM users WHERE age > ?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(q, " ALLOW FILTERING") {
+		t.Errorf("expected ALLOW FILTERING to be appended, got %q", q)
+	}
+
+	q, _ = auto.applyFilteringPolicy("SELECT * FROM users WHERE age > ? ALLOW FILTERING")
+	if strings.Count(q, "ALLOW FILTERING") != 1 {
+		t.Errorf("expected ALLOW FILTERING once, got %q", q)
+	}
+
+	guard := &Paginator{Opts: Options{Keys: keys, Filters: filters, Filtering: FilteringGuard}}
+	if _, err := guard.applyFilteringPolicy("SELECT * FROM users WHERE age > ?"); !errors.Is(err, ErrFilteringRequired) {
+		t.Errorf("expected ErrFilteringRequired, got %v", err)
+	}
+
+	guard.Opts.Filters = map[string]interface{}{"user_id": "u1"}
+	if _, err := guard.applyFilteringPolicy("SELECT * FROM users ALLOW FILTERING"); !errors.Is(err, ErrFilteringRequired) {
+		t.Errorf("expected explicit ALLOW FILTERING to be refused, got %v", err)
+	}
+	if _, err := guard.applyFilteringPolicy("SELECT * FROM users WHERE user_id = ?"); err != nil {
+		t.Errorf("unexpected error for key lookup: %v", err)
+	}
+}
+
+func TestApplyFilteringPolicy_GuardWithoutKeys(t *testing.T) {
+	guard := &Paginator{Opts: Options{Filters: map[string]interface{}{"user_id": "u1"}, Filtering: FilteringGuard}}
+	_, err := guard.applyFilteringPolicy("SELECT * FROM users WHERE user_id = ?")
+	if !errors.Is(err, ErrFilteringRequired) || !strings.Contains(err.Error(), "key metadata is required") {
+		t.Errorf("expected the guard to fail closed without keys, got %v", err)
+	}
+
+	// Unfiltered queries have nothing to check
+	guard.Opts.Filters = nil
+	if _, err := guard.applyFilteringPolicy("SELECT * FROM users"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
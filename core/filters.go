@@ -2,6 +2,7 @@ package core
 
 import (
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -24,20 +25,18 @@ func buildQueryWithFilters(baseQuery string, filters map[string]interface{}) (st
 	whereClauses := []string{}
 	values := []interface{}{}
 
-	for k, v := range filters {
-		key := strings.TrimSpace(k)
-		operator := "=" // default
+	// Sort keys so the same filters always produce the same statement
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
-		// Extract operator if present
-		for _, op := range []string{">=", "<=", ">", "<", "IN"} {
-			if strings.HasSuffix(strings.ToUpper(key), op) {
-				operator = op
-				key = strings.TrimSpace(strings.TrimSuffix(key, op))
-				break
-			}
-		}
+	for _, k := range keys {
+		v := filters[k]
+		key, operator := parseFilterKey(k)
 
-		switch strings.ToUpper(operator) {
+		switch operator {
 		case "IN":
 			// Handle slice or array values for IN
 			valSlice, ok := anyToSlice(v)
@@ -58,14 +57,40 @@ func buildQueryWithFilters(baseQuery string, filters map[string]interface{}) (st
 		}
 	}
 
-	queryLower := strings.ToLower(baseQuery)
+	return appendRelations(baseQuery, whereClauses), values
+}
+
+// appendRelations adds relations to the query, starting a WHERE clause if there is none yet.
+func appendRelations(query string, relations []string) string {
+	if len(relations) == 0 {
+		return query
+	}
+
+	queryLower := strings.ToLower(query)
 	if strings.Contains(queryLower, "where") {
-		baseQuery += " AND " + strings.Join(whereClauses, " AND ")
-	} else {
-		baseQuery += " WHERE " + strings.Join(whereClauses, " AND ")
+		return query + " AND " + strings.Join(relations, " AND ")
+	}
+	return query + " WHERE " + strings.Join(relations, " AND ")
+}
+
+// parseFilterKey splits a filter key such as "age >=" or "region IN" into its
+// column name and operator. Keys without an operator default to "=".
+func parseFilterKey(k string) (string, string) {
+	key := strings.TrimSpace(k)
+	upper := strings.ToUpper(key)
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasSuffix(upper, op) {
+			return strings.TrimSpace(key[:len(key)-len(op)]), op
+		}
+	}
+
+	// IN must be a separate word so columns like "login" are not mistaken for it
+	if strings.HasSuffix(upper, " IN") {
+		return strings.TrimSpace(key[:len(key)-2]), "IN"
 	}
 
-	return baseQuery, values
+	return key, "="
 }
 
 // anyToSlice converts any slice/array into []interface{} for binding.
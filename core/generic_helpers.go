@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mitchellh/mapstructure"
@@ -24,6 +25,28 @@ func NextWithTokenAs[T any](p *Paginator, token string) ([]T, string, error) {
 	return MapTo[T](results, nextToken)
 }
 
+// GoToPageAs jumps to page n and returns typed results.
+func GoToPageAs[T any](p *Paginator, n int) ([]T, string, error) {
+	results, nextToken, err := p.GoToPage(n)
+	if err != nil {
+		return nil, "", err
+	}
+	return MapTo[T](results, nextToken)
+}
+
+// FetchPageAs is FetchPage with the rows decoded into T.
+func FetchPageAs[T any](ctx context.Context, p *Paginator, token string) (Page[T], error) {
+	page, err := p.FetchPage(ctx, token)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	rows, _, err := MapTo[T](page.Rows, "")
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Rows: rows, PageInfo: page.PageInfo}, nil
+}
+
 // mapTo decodes a slice of map[string]interface{} into a typed slice using struct tags.
 func MapTo[T any](input []map[string]interface{}, token string) ([]T, string, error) {
 	var typed []T
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxPageWalk bounds how many pages GoToPage walks when Options.MaxPageWalk is unset.
+const DefaultMaxPageWalk = 50
+
+// PageIndex caches page boundary states per query fingerprint, so GoToPage can
+// jump to a page that was reached before without walking from the start again.
+type PageIndex interface {
+	// Get returns the page state that fetches the given page (pages start at 1).
+	Get(fingerprint string, page int) ([]byte, bool)
+	// Put stores the page state that fetches the given page.
+	Put(fingerprint string, page int, state []byte)
+}
+
+// MemoryPageIndex is an in-memory PageIndex that is safe for concurrent use.
+type MemoryPageIndex struct {
+	mu    sync.RWMutex
+	pages map[string]map[int][]byte
+}
+
+// NewMemoryPageIndex creates an empty in-memory page index.
+func NewMemoryPageIndex() *MemoryPageIndex {
+	return &MemoryPageIndex{pages: map[string]map[int][]byte{}}
+}
+
+// Get implements PageIndex.
+func (m *MemoryPageIndex) Get(fingerprint string, page int) ([]byte, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.pages[fingerprint][page]
+	return state, ok
+}
+
+// Put implements PageIndex.
+func (m *MemoryPageIndex) Put(fingerprint string, page int, state []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pages[fingerprint] == nil {
+		m.pages[fingerprint] = map[int][]byte{}
+	}
+	m.pages[fingerprint][page] = state
+}
+
+// GoToPage jumps to page n (starting at 1). It walks forward from the closest known
+// page boundary and then fetches the target page. Every page is read exactly as
+// NextWithToken reads it — the same query, Adaptive fetch sizes, MaxPageBytes
+// cuts and LookAhead — so page n is the page NextWithToken paging would reach.
+// Walked pages are read in full and discarded: a page state is only valid for
+// the query that produced it, so a jump costs as much as fetching every page on
+// the way. Options.PageIndex keeps later jumps short. At most Options.MaxPageWalk
+// pages are walked; further jumps fail with ErrPageWalkLimit.
+//
+// The returned token continues forward from page n as usual; Previous on it
+// returns page n, but the chain of earlier pages is not embedded.
+func (p *Paginator) GoToPage(n int) ([]map[string]interface{}, string, error) {
+	return p.GoToPageContext(p.Opts.context(), n)
+}
+
+// GoToPageContext is GoToPage with a per-call context, used instead of Options.Context.
+func (p *Paginator) GoToPageContext(ctx context.Context, n int) ([]map[string]interface{}, string, error) {
+	if n < 1 {
+		return nil, "", fmt.Errorf("%w: page %d", ErrPageOutOfRange, n)
+	}
+
+	queryStr, bindValues, err := p.buildQuery(p.Query, queryParts{columns: p.Opts.Columns})
+	if err != nil {
+		return nil, "", err
+	}
+	fingerprint := p.fingerprint(queryStr, bindValues)
+
+	// 1️⃣ Find the closest cached boundary at or before page n (page 1 needs no state)
+	page, at := 1, TokenEnvelope{}
+	if p.Opts.PageIndex != nil {
+		for k := n; k > 1; k-- {
+			if cached, ok := p.Opts.PageIndex.Get(fingerprint, k); ok {
+				page, at = k, TokenEnvelope{State: cached}
+				break
+			}
+		}
+	}
+
+	maxWalk := p.Opts.MaxPageWalk
+	if maxWalk <= 0 {
+		maxWalk = DefaultMaxPageWalk
+	}
+	if n-page > maxWalk {
+		return nil, "", fmt.Errorf("%w: page %d is %d pages away from the closest known page", ErrPageWalkLimit, n, n-page)
+	}
+
+	// 2️⃣ Walk forward, remembering every boundary a page state alone can resume
+	start := time.Now()
+	walked := 0
+	for page < n {
+		scan, err := p.fetchPage(ctx, queryStr, bindValues, at.State, at.Skip)
+		if err != nil {
+			return nil, "", err
+		}
+		walked++
+		if len(scan.rows) == 0 || !scan.hasMore() {
+			return nil, "", fmt.Errorf("%w: page %d", ErrPageOutOfRange, n)
+		}
+		page, at = page+1, TokenEnvelope{State: scan.nextState, Skip: scan.nextSkip}
+		if p.Opts.PageIndex != nil && at.Skip == 0 {
+			p.Opts.PageIndex.Put(fingerprint, page, at.State)
+		}
+	}
+
+	// 3️⃣ Fetch the target page
+	target, err := p.fetchPage(ctx, queryStr, bindValues, at.State, at.Skip)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(target.rows) == 0 && n > 1 {
+		return nil, "", fmt.Errorf("%w: page %d", ErrPageOutOfRange, n)
+	}
+	if p.Opts.PageIndex != nil && len(target.nextState) > 0 && target.nextSkip == 0 {
+		p.Opts.PageIndex.Put(fingerprint, n+1, target.nextState)
+	}
+
+	p.Opts.log("page_jumped", map[string]interface{}{
+		"page":         n,
+		"pages_walked": walked,
+		"duration_ms":  time.Since(start).Milliseconds(),
+	})
+
+	// With LookAhead the end is known, so the last page gets no next token
+	if p.Opts.LookAhead && !target.hasMore() {
+		return target.rows, "", nil
+	}
+	next := TokenEnvelope{State: target.nextState, Skip: target.nextSkip, Prev: at.Encode()}
+	return target.rows, next.Encode(), nil
+}
+
+// fingerprint identifies a built query, its bound values and the page size.
+func (p *Paginator) fingerprint(queryStr string, bindValues []interface{}) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%d|%v", queryStr, p.PageSize, bindValues)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
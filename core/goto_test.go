@@ -0,0 +1,121 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+func TestPaginator_GoToPage(t *testing.T) {
+	session := newTableSession("items", numberedRows(45))
+	index := core.NewMemoryPageIndex()
+	p := core.NewPaginator(session, "SELECT * FROM items", core.Options{
+		PageSize:  10,
+		PageIndex: index,
+		Keys:      &core.TableKeys{PartitionKeys: []string{"id"}},
+	})
+
+	results, token, err := p.GoToPage(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 10 || results[0]["id"] != 20 {
+		t.Fatalf("expected page 3 to start at id 20, got %v", results)
+	}
+
+	// The walk runs the same query as the page, so its page states fit it
+	queries := session.Queries()
+	if len(queries) != 3 || queries[0].Statement != queries[2].Statement {
+		t.Errorf("expected the walk to run the page's query, got %+v", queries)
+	}
+
+	// The token continues from page 4
+	next, _, err := p.NextWithToken(token)
+	if err != nil || next[0]["id"] != 30 {
+		t.Fatalf("expected page 4 to start at id 30, got %v (%v)", next, err)
+	}
+
+	// Page 5 is reached from the cached boundary of page 4 without walking
+	before := len(session.Queries())
+	results, _, err = p.GoToPage(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 5 || results[0]["id"] != 40 {
+		t.Fatalf("expected last page with 5 rows, got %v", results)
+	}
+	if walked := len(session.Queries()) - before; walked != 2 {
+		t.Errorf("expected one walk query and one fetch, got %d queries", walked)
+	}
+}
+
+func TestPaginator_GoToPage_Errors(t *testing.T) {
+	p := core.NewPaginator(newTableSession("items", numberedRows(25)), "SELECT * FROM items", core.Options{
+		PageSize:    10,
+		MaxPageWalk: 3,
+	})
+
+	if _, _, err := p.GoToPage(0); !errors.Is(err, core.ErrPageOutOfRange) {
+		t.Errorf("expected ErrPageOutOfRange for page 0, got %v", err)
+	}
+	if _, _, err := p.GoToPage(4); !errors.Is(err, core.ErrPageOutOfRange) {
+		t.Errorf("expected ErrPageOutOfRange past the end, got %v", err)
+	}
+	if _, _, err := p.GoToPage(10); !errors.Is(err, core.ErrPageWalkLimit) {
+		t.Errorf("expected ErrPageWalkLimit, got %v", err)
+	}
+}
+
+func TestPaginator_GoToPageMatchesTokenPaging(t *testing.T) {
+	opts := core.Options{PageSize: 10, MaxPageBytes: 2000, LookAhead: true}
+	newPaginator := func() *core.Paginator {
+		return core.NewPaginator(newTableSession("blobs", blobRows(40, 100, 900, 100, 5000)), "SELECT * FROM blobs", opts)
+	}
+
+	// The pages NextWithToken produces, cut by MaxPageBytes
+	var pages [][]int
+	p := newPaginator()
+	token := ""
+	for i := 0; i < 40; i++ {
+		rows, next, err := p.NextWithToken(token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pages = append(pages, rowIDs(rows))
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	p = newPaginator()
+	for n, want := range pages {
+		rows, next, err := p.GoToPage(n + 1)
+		if err != nil {
+			t.Fatalf("page %d: unexpected error: %v", n+1, err)
+		}
+		if !reflect.DeepEqual(rowIDs(rows), want) {
+			t.Errorf("page %d: expected %v, got %v", n+1, want, rowIDs(rows))
+		}
+		// LookAhead knows the last page, which gets no next token
+		if last := n == len(pages)-1; last != (next == "") {
+			t.Errorf("page %d: unexpected next token %q", n+1, next)
+		}
+	}
+}
+
+func TestPaginator_GoToPageContext(t *testing.T) {
+	p := core.NewPaginator(newTableSession("items", numberedRows(45)), "SELECT * FROM items", core.Options{PageSize: 10})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := p.GoToPageContext(ctx, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if rows, _, err := p.GoToPageContext(context.Background(), 3); err != nil || rows[0]["id"] != 20 {
+		t.Errorf("expected page 3, got %v (%v)", rows, err)
+	}
+}
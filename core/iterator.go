@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// PageIterator walks a paginator page by page:
+//
+//	it := p.Pages("")
+//	defer it.Close()
+//	for it.Next() {
+//		process(it.Rows())
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// With Options.PrefetchDepth > 0 the following pages are fetched in the
+// background while the caller processes the current one.
+type PageIterator struct {
+	p      *Paginator
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	token string // token of the next page to fetch (synchronous mode)
+	rows  []map[string]interface{}
+	next  string
+	info  PageInfo
+	err   error
+	done  bool
+
+	pages chan pageResult // prefetched pages, nil in synchronous mode
+	wg    sync.WaitGroup
+}
+
+type pageResult struct {
+	rows  []map[string]interface{}
+	token string
+	info  PageInfo
+	err   error
+}
+
+// Pages returns an iterator starting at the given token ("" for the first page).
+// The iterator stops when Options.Context is cancelled; call Close to release it early.
+func (p *Paginator) Pages(token string) *PageIterator {
+	return p.pages(p.Opts.context(), token, p.Opts.PrefetchDepth)
+}
+
+// PagesContext is Pages with a per-call context, used instead of Options.Context.
+func (p *Paginator) PagesContext(ctx context.Context, token string) *PageIterator {
+	return p.pages(ctx, token, p.Opts.PrefetchDepth)
+}
+
+// pages creates an iterator bound to ctx that prefetches depth pages (0: none).
+func (p *Paginator) pages(parent context.Context, token string, depth int) *PageIterator {
+	ctx, cancel := context.WithCancel(parent)
+	it := &PageIterator{p: p, ctx: ctx, cancel: cancel, token: token}
+
+	if depth > 0 {
+		it.pages = make(chan pageResult, depth)
+		it.wg.Add(1)
+		go it.prefetch(token)
+	}
+	return it
+}
+
+// Next advances to the next page. It returns false at the end of the
+// results, on error or after Close.
+func (it *PageIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	var r pageResult
+	if err := it.ctx.Err(); err != nil {
+		r.err = err
+	} else if it.pages != nil {
+		var ok bool
+		r, ok = <-it.pages
+		if !ok {
+			// producer stopped without a final page: cancelled
+			r.err = it.ctx.Err()
+		}
+	} else {
+		r.rows, r.info, r.err = it.p.fetchWithToken(it.ctx, it.token)
+		r.token = r.info.NextToken
+		it.token = r.token
+	}
+
+	if r.err != nil {
+		it.err = r.err
+		it.finish()
+		return false
+	}
+
+	more := r.info.HasMore
+	if len(r.rows) == 0 && !more {
+		it.finish()
+		return false
+	}
+
+	it.rows, it.next, it.info = r.rows, r.token, r.info
+	if !more {
+		it.next, it.info.NextToken = "", ""
+		// last page: deliver it, then stop on the following call
+		it.done = true
+		it.release()
+	}
+	return true
+}
+
+// Rows returns the rows of the current page.
+func (it *PageIterator) Rows() []map[string]interface{} {
+	return it.rows
+}
+
+// Token returns the token that resumes right after the current page ("" at the end).
+func (it *PageIterator) Token() string {
+	return it.next
+}
+
+// Info describes how the current page was fetched.
+func (it *PageIterator) Info() PageInfo {
+	return it.info
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iterator and waits for the background fetcher to exit.
+// It is safe to call Close more than once.
+func (it *PageIterator) Close() {
+	it.finish()
+}
+
+// prefetch fetches pages ahead of the caller until the end, an error or cancellation.
+func (it *PageIterator) prefetch(token string) {
+	defer it.wg.Done()
+	defer close(it.pages)
+
+	for {
+		var r pageResult
+		r.rows, r.info, r.err = it.p.fetchWithToken(it.ctx, token)
+		r.token = r.info.NextToken
+		if r.err != nil && it.ctx.Err() != nil {
+			return // cancelled; Next reports the context error
+		}
+
+		select {
+		case it.pages <- r:
+		case <-it.ctx.Done():
+			return
+		}
+
+		if r.err != nil || !r.info.HasMore {
+			return
+		}
+		token = r.token
+	}
+}
+
+// finish marks the iterator done and releases its resources.
+func (it *PageIterator) finish() {
+	it.done = true
+	it.rows = nil
+	it.release()
+}
+
+// release cancels the internal context and waits for the prefetcher.
+func (it *PageIterator) release() {
+	it.cancel()
+	it.wg.Wait()
+}
@@ -0,0 +1,131 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+func rowIDs(rows []map[string]interface{}) []int {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row["id"].(int)
+	}
+	return ids
+}
+
+func TestPages_IteratesAllPages(t *testing.T) {
+	for _, depth := range []int{0, 1, 3} {
+		session := newTableSession("users", numberedRows(23))
+		p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5, PrefetchDepth: depth})
+
+		it := p.Pages("")
+		var ids []int
+		pages := 0
+		for it.Next() {
+			ids = append(ids, rowIDs(it.Rows())...)
+			pages++
+		}
+		it.Close()
+
+		if err := it.Err(); err != nil {
+			t.Fatalf("depth %d: unexpected error: %v", depth, err)
+		}
+		if pages != 5 || len(ids) != 23 || ids[22] != 22 {
+			t.Errorf("depth %d: got %d pages and ids %v", depth, pages, ids)
+		}
+		if it.Token() != "" {
+			t.Errorf("depth %d: expected empty token after the last page", depth)
+		}
+	}
+}
+
+func TestPages_TokenResumes(t *testing.T) {
+	session := newTableSession("users", numberedRows(12))
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5, PrefetchDepth: 2})
+
+	it := p.Pages("")
+	if !it.Next() {
+		t.Fatalf("expected a first page, err: %v", it.Err())
+	}
+	token := it.Token()
+	it.Close()
+
+	rows, _, err := p.NextWithToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rowIDs(rows); got[0] != 5 {
+		t.Errorf("expected resume at id 5, got %v", got)
+	}
+}
+
+func TestPages_PrefetchesAhead(t *testing.T) {
+	session := newTableSession("users", numberedRows(50))
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5, PrefetchDepth: 2})
+
+	it := p.Pages("")
+	defer it.Close()
+	if !it.Next() {
+		t.Fatalf("expected a first page, err: %v", it.Err())
+	}
+
+	// page 1 handed out, pages 2-3 buffered, page 4 fetched and waiting to be sent
+	deadline := time.Now().Add(time.Second)
+	for len(session.Queries()) < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(session.Queries()); n != 4 {
+		t.Errorf("expected 4 queries with depth 2, got %d", n)
+	}
+}
+
+func TestPages_ContextCancellation(t *testing.T) {
+	for _, depth := range []int{0, 2} {
+		ctx, cancel := context.WithCancel(context.Background())
+		session := newTableSession("users", numberedRows(100))
+		p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5, PrefetchDepth: depth, Context: ctx})
+
+		it := p.Pages("")
+		if !it.Next() {
+			t.Fatalf("depth %d: expected a first page, err: %v", depth, it.Err())
+		}
+		cancel()
+
+		if it.Next() {
+			t.Errorf("depth %d: expected iteration to stop after cancellation", depth)
+		}
+		if !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("depth %d: expected context.Canceled, got %v", depth, it.Err())
+		}
+		it.Close()
+	}
+}
+
+func TestPages_CloseStopsPrefetcher(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 20; i++ {
+		session := newTableSession("users", numberedRows(100))
+		p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5, PrefetchDepth: 3})
+		it := p.Pages("")
+		it.Next()
+		it.Close()
+		it.Close()
+		if it.Next() {
+			t.Fatal("expected Next to return false after Close")
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines leaked: %d before, %d after", before, after)
+	}
+}
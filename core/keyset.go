@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Keyset pagination pages by clustering-column cursors instead of driver page
+// state. Every keyset token carries the clustering values of the first and last
+// row of its page, so the same token can be used in both directions:
+//
+//	NextWithToken(token) returns the rows after the page
+//	Previous(token)      returns the rows before the page
+//
+// The PrevToken of a keyset Page carries only the first cursor, so it reads
+// the rows before the page with NextWithToken and FetchPage as well.
+//
+// Backward pages are read with the clustering ORDER BY flipped and returned in
+// natural order. Keyset pagination needs clustering columns from Options.Keys or
+// Options.Schema and a query restricted to a single partition. The cursor
+// relation would apply to every partition of an IN list or token range and skip
+// rows, so those fail with ErrKeysetUnavailable; use MultiPartitionPaginator.
+
+// Last returns the last page of the query in natural order, with a keyset token.
+// Use Previous on the token to scroll upwards.
+func (p *Paginator) Last() ([]map[string]interface{}, string, error) {
+	results, info, err := p.fetchKeyset(p.Opts.context(), &TokenEnvelope{}, true)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, info.NextToken, nil
+}
+
+// TokenAt returns a keyset token positioned on the given row: NextWithToken
+// continues after it and Previous returns the rows before it.
+func (p *Paginator) TokenAt(row map[string]interface{}) (string, error) {
+	columns, _, err := p.keysetColumns()
+	if err != nil {
+		return "", err
+	}
+
+	cursor, err := rowCursor(row, columns)
+	if err != nil {
+		return "", err
+	}
+
+	env := TokenEnvelope{First: cursor, Last: cursor}
+	return env.Encode(), nil
+}
+
+// isKeyset reports whether the envelope should be served by keyset pagination.
+func (p *Paginator) isKeyset(env *TokenEnvelope) bool {
+	if len(env.First) > 0 || len(env.Last) > 0 {
+		return true
+	}
+	return p.Opts.Keyset && len(env.State) == 0
+}
+
+// fetchKeyset reads the page after env.Last, or before env.First when backward is set.
+// A missing cursor starts from the beginning (forward) or the end (backward).
+func (p *Paginator) fetchKeyset(ctx context.Context, env *TokenEnvelope, backward bool) ([]map[string]interface{}, PageInfo, error) {
+	columns, descending, err := p.keysetColumns()
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	// 1️⃣ Build the cursor relation; "after" follows the clustering order
+	parts := queryParts{columns: append([]string(nil), p.Opts.Columns...)}
+	var added []string
+	if len(parts.columns) > 0 {
+		// the cursor columns must be selected to build the next token, and are
+		// dropped from the rows again unless the caller selected them
+		for _, c := range columns {
+			if !contains(parts.columns, c) {
+				parts.columns = append(parts.columns, c)
+				added = append(added, c)
+			}
+		}
+	}
+	cursor := env.Last
+	if backward {
+		cursor = env.First
+	}
+	if len(cursor) > 0 {
+		if len(cursor) != len(columns) {
+			return nil, PageInfo{}, fmt.Errorf("%w: cursor does not match keyset columns", ErrInvalidToken)
+		}
+		values := make([]interface{}, len(cursor))
+		for i, tv := range cursor {
+			if values[i], err = tv.Decode(); err != nil {
+				return nil, PageInfo{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+			}
+		}
+
+		operator := ">"
+		if descending != backward {
+			operator = "<"
+		}
+		parts.relations = []string{tupleRelation(columns, operator)}
+		parts.values = values
+	}
+
+	// 2️⃣ Backward pages flip the clustering order
+	if backward {
+		direction := "DESC"
+		if descending {
+			direction = "ASC"
+		}
+		order := make([]string, len(columns))
+		for i, c := range columns {
+			order[i] = c + " " + direction
+		}
+		parts.orderBy = strings.Join(order, ", ")
+	}
+
+	queryStr, bindValues, err := p.buildQuery(p.Query, parts)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	page, err := p.fetchPage(ctx, queryStr, bindValues, nil, 0)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	results := page.rows
+	if len(results) == 0 {
+		info := page.info("")
+		info.HasMore = backward && len(cursor) > 0
+		return results, info, nil
+	}
+
+	// 3️⃣ Return rows in natural order with cursors for both directions
+	if backward {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
+
+	first, err := rowCursor(results[0], columns)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	last, err := rowCursor(results[len(results)-1], columns)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	for _, row := range results {
+		for _, c := range added {
+			delete(row, c)
+		}
+	}
+
+	next := TokenEnvelope{First: first, Last: last}
+	info := page.info(next.Encode())
+
+	// Backward pages from a cursor are followed by the page they were read
+	// from; an exhausted backward page reached the start
+	info.HasMore = !page.exhausted
+	if backward {
+		info.HasMore = len(cursor) > 0
+	}
+	atStart := len(cursor) == 0 && !backward
+	if backward && page.exhausted {
+		atStart = true
+	}
+	if !atStart {
+		before := TokenEnvelope{First: first}
+		info.PrevToken = before.Encode()
+	}
+	return results, info, nil
+}
+
+// keysetColumns returns the clustering columns and whether they are stored descending.
+func (p *Paginator) keysetColumns() ([]string, bool, error) {
+	keys := p.keys()
+	if keys == nil || len(keys.ClusteringKeys) == 0 {
+		return nil, false, fmt.Errorf("%w: no clustering columns known", ErrKeysetUnavailable)
+	}
+
+	if reason := p.multiPartition(keys); reason != "" {
+		return nil, false, fmt.Errorf("%w: %s; use MultiPartitionPaginator", ErrKeysetUnavailable, reason)
+	}
+
+	columns := keys.KeysetColumns()
+	descending := strings.EqualFold(keys.ClusteringOrder[columns[0]], "DESC")
+	for _, c := range columns[1:] {
+		if strings.EqualFold(keys.ClusteringOrder[c], "DESC") != descending {
+			return nil, false, fmt.Errorf("%w: mixed clustering order", ErrKeysetUnavailable)
+		}
+	}
+
+	return columns, descending, nil
+}
+
+// multiPartition describes a restriction that lets the query read more than
+// one partition: an IN on a partition key column, in the filters or the base
+// query, or a token range. It returns "" for other queries.
+func (p *Paginator) multiPartition(keys *TableKeys) string {
+	if p.Opts.TokenRange != nil {
+		return "query is restricted to a token range"
+	}
+	for k := range p.Opts.Filters {
+		column, operator := parseFilterKey(k)
+		if operator == "IN" && containsColumn(keys.PartitionKeys, unquote(column)) {
+			return fmt.Sprintf("partition key %q is restricted by IN", column)
+		}
+	}
+	for _, column := range keys.PartitionKeys {
+		in := regexp.MustCompile(`(?i)(^|[\s(,])` + regexp.QuoteMeta(column) + `\)?\s+IN\b`)
+		if in.MatchString(p.Query) {
+			return fmt.Sprintf("partition key %q is restricted by IN", column)
+		}
+	}
+	return ""
+}
+
+// tupleRelation builds "(a, b) > (?, ?)", or "a > ?" for a single column.
+func tupleRelation(columns []string, operator string) string {
+	if len(columns) == 1 {
+		return columns[0] + " " + operator + " ?"
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	return "(" + strings.Join(columns, ", ") + ") " + operator + " (" + marks + ")"
+}
+
+// rowCursor extracts the keyset values of a row.
+func rowCursor(row map[string]interface{}, columns []string) ([]TypedValue, error) {
+	cursor := make([]TypedValue, len(columns))
+	for i, c := range columns {
+		v, ok := row[c]
+		if !ok {
+			return nil, fmt.Errorf("%w: row has no %q column", ErrKeysetUnavailable, c)
+		}
+		tv, err := NewTypedValue(v)
+		if err != nil {
+			return nil, err
+		}
+		cursor[i] = tv
+	}
+	return cursor, nil
+}
@@ -0,0 +1,195 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// timelineSession holds n messages of one room with an increasing "ts"
+// clustering column.
+func timelineSession(n int) *caspagetest.Session {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"room": "general", "ts": i}
+	}
+	s := caspagetest.NewSession()
+	s.AddTable("messages", rows)
+	return s
+}
+
+func timestamps(rows []map[string]interface{}) []int {
+	out := make([]int, len(rows))
+	for i, r := range rows {
+		out[i] = r["ts"].(int)
+	}
+	return out
+}
+
+func TestPaginator_KeysetBackward(t *testing.T) {
+	p := core.NewPaginator(timelineSession(25), "SELECT * FROM messages", core.Options{
+		PageSize: 10,
+		Filters:  map[string]interface{}{"room": "general"},
+		Keys:     &core.TableKeys{PartitionKeys: []string{"room"}, ClusteringKeys: []string{"ts"}},
+	})
+
+	last, token, err := p.Last()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := timestamps(last); !reflect.DeepEqual(got, []int{15, 16, 17, 18, 19, 20, 21, 22, 23, 24}) {
+		t.Fatalf("unexpected last page: %v", got)
+	}
+
+	middle, middleToken, err := p.Previous(token)
+	if err != nil || timestamps(middle)[0] != 5 || len(middle) != 10 {
+		t.Fatalf("unexpected previous page: %v (%v)", timestamps(middle), err)
+	}
+
+	first, _, err := p.Previous(middleToken)
+	if err != nil || !reflect.DeepEqual(timestamps(first), []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("unexpected first page: %v (%v)", timestamps(first), err)
+	}
+
+	// The same token also works forward
+	again, _, err := p.NextWithToken(middleToken)
+	if err != nil || !reflect.DeepEqual(timestamps(again), timestamps(last)) {
+		t.Fatalf("expected forward page to match last page, got %v (%v)", timestamps(again), err)
+	}
+}
+
+func TestPaginator_TokenAt(t *testing.T) {
+	p := core.NewPaginator(timelineSession(25), "SELECT * FROM messages", core.Options{
+		PageSize: 5,
+		Keyset:   true,
+		Keys:     &core.TableKeys{PartitionKeys: []string{"room"}, ClusteringKeys: []string{"ts"}},
+	})
+
+	first, _, err := p.Next()
+	if err != nil || !reflect.DeepEqual(timestamps(first), []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("unexpected first keyset page: %v (%v)", timestamps(first), err)
+	}
+
+	token, err := p.TokenAt(map[string]interface{}{"ts": 12})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	before, _, _ := p.Previous(token)
+	after, _, _ := p.NextWithToken(token)
+	if !reflect.DeepEqual(timestamps(before), []int{7, 8, 9, 10, 11}) {
+		t.Errorf("unexpected rows before 12: %v", timestamps(before))
+	}
+	if !reflect.DeepEqual(timestamps(after), []int{13, 14, 15, 16, 17}) {
+		t.Errorf("unexpected rows after 12: %v", timestamps(after))
+	}
+}
+
+func TestPaginator_KeysetUnavailable(t *testing.T) {
+	p := core.NewPaginator(timelineSession(5), "SELECT * FROM messages", core.Options{})
+	if _, _, err := p.Last(); !errors.Is(err, core.ErrKeysetUnavailable) {
+		t.Fatalf("expected ErrKeysetUnavailable, got %v", err)
+	}
+}
+
+func TestPaginator_KeysetRejectsSeveralPartitions(t *testing.T) {
+	keys := &core.TableKeys{PartitionKeys: []string{"room"}, ClusteringKeys: []string{"ts"}}
+	r := core.FullRing()
+	cases := map[string]*core.Paginator{
+		"IN filter": core.NewPaginator(timelineSession(10), "SELECT * FROM messages", core.Options{
+			PageSize: 5, Keyset: true, Keys: keys,
+			Filters: map[string]interface{}{"room IN": []string{"general", "random"}},
+		}),
+		"IN in the query": core.NewPaginator(timelineSession(10), "SELECT * FROM messages WHERE room IN ('general', 'random')", core.Options{
+			PageSize: 5, Keyset: true, Keys: keys,
+		}),
+		"token range": core.NewPaginator(timelineSession(10), "SELECT * FROM messages", core.Options{
+			PageSize: 5, Keyset: true, Keys: keys, TokenRange: &r,
+		}),
+	}
+	for name, p := range cases {
+		if _, _, err := p.Next(); !errors.Is(err, core.ErrKeysetUnavailable) || !strings.Contains(err.Error(), "MultiPartitionPaginator") {
+			t.Errorf("%s: expected ErrKeysetUnavailable pointing to MultiPartitionPaginator, got %v", name, err)
+		}
+		if _, _, err := p.Last(); !errors.Is(err, core.ErrKeysetUnavailable) {
+			t.Errorf("%s: expected ErrKeysetUnavailable for Last, got %v", name, err)
+		}
+	}
+
+	// A single partition with an IN on a clustering column still pages
+	p := core.NewPaginator(timelineSession(10), "SELECT * FROM messages", core.Options{
+		PageSize: 5, Keyset: true, Keys: keys,
+		Filters: map[string]interface{}{"room": "general", "ts IN": []int{1, 2}},
+	})
+	if rows, _, err := p.Next(); err != nil || len(rows) != 2 {
+		t.Errorf("expected the single partition to page, got %v (%v)", rows, err)
+	}
+}
+
+func TestTypedValue_RoundTrip(t *testing.T) {
+	values := []interface{}{
+		nil, "text", true, 42, int64(1 << 40), int16(7), 1.5,
+		[]byte("blob"),
+		time.Date(2024, 3, 1, 12, 0, 0, 123, time.UTC),
+		gocql.TimeUUID(),
+		big.NewInt(99),
+	}
+
+	for _, v := range values {
+		tv, err := core.NewTypedValue(v)
+		if err != nil {
+			t.Fatalf("encode %v: %v", v, err)
+		}
+		got, err := tv.Decode()
+		if err != nil {
+			t.Fatalf("decode %v: %v", v, err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("round trip of %T: got %#v, want %#v", v, got, v)
+		}
+	}
+}
+
+func TestPaginator_KeysetDropsCursorColumns(t *testing.T) {
+	s := caspagetest.NewSession()
+	rows := make([]map[string]interface{}, 8)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"room": "general", "ts": i, "body": fmt.Sprintf("message %d", i)}
+	}
+	s.AddTable("messages", rows)
+	p := core.NewPaginator(s, "SELECT * FROM messages", core.Options{
+		PageSize: 5,
+		Keyset:   true,
+		Columns:  []string{"body"},
+		Filters:  map[string]interface{}{"room": "general"},
+		Keys:     &core.TableKeys{PartitionKeys: []string{"room"}, ClusteringKeys: []string{"ts"}},
+	})
+
+	first, token, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _, err := p.NextWithToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 5 || len(second) != 3 || second[0]["body"] != "message 5" {
+		t.Fatalf("unexpected pages %v %v", first, second)
+	}
+
+	// ts is selected for the cursor only
+	for _, row := range append(first, second...) {
+		if len(row) != 1 {
+			t.Errorf("expected only the selected column, got %v", row)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// MergeOrder is the column used to merge rows coming from several sources.
+// Every source must already return its rows ordered by that column in the same
+// direction, typically because it is the clustering column.
+type MergeOrder struct {
+	Column     string
+	Descending bool
+}
+
+// less reports whether row a sorts strictly before row b.
+func (o MergeOrder) less(a, b map[string]interface{}) bool {
+	c := compareValues(a[o.Column], b[o.Column])
+	if o.Descending {
+		c = -c
+	}
+	return c < 0
+}
+
+// mergeSource is one input of a merge: a paginator and its position.
+type mergeSource struct {
+	p *Paginator
+
+	state    []byte                   // page state the buffered page was fetched with
+	offset   int                      // rows of that page already consumed
+	buffer   []map[string]interface{} // rows not consumed yet
+	next     []byte                   // position of the following page: page state
+	nextSkip int                      // ... and rows of it to skip
+	loaded   bool
+	done     bool
+}
+
+// newMergeSources positions one source per paginator according to the cursors.
+// Empty cursors start every source from the beginning.
+func newMergeSources(paginators []*Paginator, cursors []SubCursor) ([]*mergeSource, error) {
+	if len(cursors) > 0 && len(cursors) != len(paginators) {
+		return nil, fmt.Errorf("%w: token has %d sources, expected %d", ErrInvalidToken, len(cursors), len(paginators))
+	}
+
+	sources := make([]*mergeSource, len(paginators))
+	for i, p := range paginators {
+		s := &mergeSource{p: p}
+		if len(cursors) > 0 {
+			s.state, s.offset, s.done = cursors[i].State, cursors[i].Skip, cursors[i].Done
+		}
+		sources[i] = s
+	}
+	return sources, nil
+}
+
+// load makes sure the buffer holds a row unless the source is exhausted.
+func (s *mergeSource) load(ctx context.Context) error {
+	for len(s.buffer) == 0 && !s.done {
+		state, skip := s.state, s.offset
+		if s.loaded {
+			if len(s.next) == 0 && s.nextSkip == 0 {
+				s.done = true
+				return nil
+			}
+			state, skip = s.next, s.nextSkip
+		}
+
+		rows, next, nextSkip, err := s.p.fetchState(ctx, state, skip)
+		if err != nil {
+			return err
+		}
+
+		s.loaded = true
+		s.state, s.offset, s.next, s.nextSkip = state, skip, next, nextSkip
+		s.buffer = rows
+		if len(rows) == 0 && len(next) == 0 && nextSkip == 0 {
+			s.done = true
+		}
+	}
+	return nil
+}
+
+// pop consumes the head row.
+func (s *mergeSource) pop() map[string]interface{} {
+	row := s.buffer[0]
+	s.buffer = s.buffer[1:]
+	s.offset++
+	return row
+}
+
+// cursor returns the position right after the last consumed row.
+func (s *mergeSource) cursor() SubCursor {
+	switch {
+	case s.done:
+		return SubCursor{Done: true}
+	case !s.loaded || len(s.buffer) > 0:
+		return SubCursor{State: s.state, Skip: s.offset}
+	case len(s.next) > 0 || s.nextSkip > 0:
+		return SubCursor{State: s.next, Skip: s.nextSkip}
+	}
+	return SubCursor{Done: true}
+}
+
+// mergePage k-way merges up to pageSize rows from the sources by order.
+// Ties go to the source listed first, so the merge is stable.
+func mergePage(ctx context.Context, sources []*mergeSource, order MergeOrder, pageSize int) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, 0, pageSize)
+
+	for len(results) < pageSize {
+		best := -1
+		for i, s := range sources {
+			if err := s.load(ctx); err != nil {
+				return nil, err
+			}
+			if len(s.buffer) == 0 {
+				continue
+			}
+			if best < 0 || order.less(s.buffer[0], sources[best].buffer[0]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break // every source is exhausted
+		}
+		results = append(results, sources[best].pop())
+	}
+
+	return results, nil
+}
+
+// mergeCursors collects the cursors of all sources, or nil when all are exhausted.
+func mergeCursors(sources []*mergeSource) []SubCursor {
+	cursors := make([]SubCursor, len(sources))
+	exhausted := true
+	for i, s := range sources {
+		cursors[i] = s.cursor()
+		exhausted = exhausted && cursors[i].Done
+	}
+	if exhausted {
+		return nil
+	}
+	return cursors
+}
+
+// fetchState builds the paginator's query and fetches the page at the given position.
+func (p *Paginator) fetchState(ctx context.Context, state []byte, skip int) ([]map[string]interface{}, []byte, int, error) {
+	queryStr, bindValues, err := p.buildQuery(p.Query, queryParts{columns: p.Opts.Columns})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	page, err := p.fetchPage(ctx, queryStr, bindValues, state, skip)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return page.rows, page.nextState, page.nextSkip, nil
+}
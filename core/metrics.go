@@ -8,3 +8,10 @@ type MetricsCollector interface {
 	ObservePageFetch(rows int, duration time.Duration)
 	ObserveError(err error)
 }
+
+// FetchSizeObserver is an optional extension of MetricsCollector. Collectors
+// implementing it receive the driver fetch size chosen by Options.Adaptive and
+// the observed average row size after every page.
+type FetchSizeObserver interface {
+	ObserveFetchSize(size int, bytesPerRow int)
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MultiPartitionPaginator pages over several partitions of the same table and
+// k-way merges their rows by a clustering column, so pages are globally ordered
+// instead of grouped by partition as with "WHERE key IN (...)".
+//
+// One sub-paginator runs per partition; the token records every sub-cursor.
+type MultiPartitionPaginator struct {
+	Order    MergeOrder
+	PageSize int
+	Opts     Options
+
+	partitions []*Paginator
+}
+
+// PartitionValues builds one partition filter per value of a single-column partition key.
+func PartitionValues(column string, values ...interface{}) []map[string]interface{} {
+	partitions := make([]map[string]interface{}, len(values))
+	for i, v := range values {
+		partitions[i] = map[string]interface{}{column: v}
+	}
+	return partitions
+}
+
+// NewMultiPartitionPaginator creates a merged paginator. Each entry of partitions
+// holds the partition key filters of one partition and is combined with opts.Filters.
+func NewMultiPartitionPaginator(session CassandraSession, query string, partitions []map[string]interface{}, order MergeOrder, opts Options) *MultiPartitionPaginator {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	subs := make([]*Paginator, len(partitions))
+	for i, partition := range partitions {
+		subOpts := opts
+		subOpts.Filters = make(map[string]interface{}, len(opts.Filters)+len(partition))
+		for k, v := range opts.Filters {
+			subOpts.Filters[k] = v
+		}
+		for k, v := range partition {
+			subOpts.Filters[k] = v
+		}
+		subOpts.PageSize = pageSize
+		subs[i] = NewPaginator(session, query, subOpts)
+	}
+
+	return &MultiPartitionPaginator{
+		Order:      order,
+		PageSize:   pageSize,
+		Opts:       opts,
+		partitions: subs,
+	}
+}
+
+// Next fetches the first merged page.
+func (m *MultiPartitionPaginator) Next() ([]map[string]interface{}, string, error) {
+	return m.NextWithToken("")
+}
+
+// NextContext is Next with a per-call context, used instead of Options.Context.
+func (m *MultiPartitionPaginator) NextContext(ctx context.Context) ([]map[string]interface{}, string, error) {
+	return m.NextWithTokenContext(ctx, "")
+}
+
+// NextWithToken fetches the merged page at the given composite token.
+func (m *MultiPartitionPaginator) NextWithToken(token string) ([]map[string]interface{}, string, error) {
+	return m.NextWithTokenContext(m.Opts.context(), token)
+}
+
+// NextWithTokenContext is NextWithToken with a per-call context, used instead of Options.Context.
+func (m *MultiPartitionPaginator) NextWithTokenContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	ct, err := DecodeCompositeToken(token)
+	if err != nil {
+		m.Opts.observeInvalidToken(token, err)
+		return nil, "", ErrInvalidToken
+	}
+
+	sources, err := newMergeSources(m.partitions, ct.Parts)
+	if err != nil {
+		m.Opts.observeInvalidToken(token, err)
+		return nil, "", err
+	}
+
+	start := time.Now()
+	results, err := mergePage(ctx, sources, m.Order, m.PageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	m.Opts.log("partitions_merged", map[string]interface{}{
+		"partitions":   len(m.partitions),
+		"rows_fetched": len(results),
+		"duration_ms":  time.Since(start).Milliseconds(),
+	})
+
+	cursors := mergeCursors(sources)
+	if cursors == nil {
+		return results, "", nil
+	}
+	return results, EncodeCompositeToken(CompositeToken{Parts: cursors, Prev: token}), nil
+}
+
+// Previous fetches the page before the one the token leads to, using the embedded previous token.
+func (m *MultiPartitionPaginator) Previous(token string) ([]map[string]interface{}, string, error) {
+	return m.PreviousContext(m.Opts.context(), token)
+}
+
+// PreviousContext is Previous with a per-call context, used instead of Options.Context.
+func (m *MultiPartitionPaginator) PreviousContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	ct, err := DecodeCompositeToken(token)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if ct.Prev == "" {
+		return nil, "", ErrNoPrevToken
+	}
+	return m.NextWithTokenContext(ctx, ct.Prev)
+}
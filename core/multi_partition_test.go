@@ -0,0 +1,115 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// feedSession holds the posts of users a, b and c, each partition ordered by
+// ts. The users post at interleaved timestamps.
+func feedSession() *caspagetest.Session {
+	feeds := map[string][]int{
+		"a": {1, 4, 7, 10, 13},
+		"b": {2, 5, 8, 11, 14},
+		"c": {3, 6, 9},
+	}
+	var rows []map[string]interface{}
+	for _, user := range []string{"a", "b", "c"} {
+		for _, ts := range feeds[user] {
+			rows = append(rows, map[string]interface{}{"user_id": user, "ts": ts})
+		}
+	}
+	s := caspagetest.NewSession()
+	s.AddTable("posts", rows)
+	return s
+}
+
+func TestMultiPartitionPaginator(t *testing.T) {
+	session := feedSession()
+	m := core.NewMultiPartitionPaginator(session, "SELECT * FROM posts",
+		core.PartitionValues("user_id", "a", "b", "c"),
+		core.MergeOrder{Column: "ts"},
+		core.Options{PageSize: 4},
+	)
+
+	var all []int
+	token := ""
+	for page := 0; page < 10; page++ {
+		rows, next, err := m.NextWithToken(token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) > 4 {
+			t.Fatalf("page larger than page size: %d", len(rows))
+		}
+		all = append(all, timestamps(rows)...)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 14}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("unexpected merged order:\n got %v\nwant %v", all, want)
+	}
+}
+
+func TestMultiPartitionPaginator_InvalidToken(t *testing.T) {
+	m := core.NewMultiPartitionPaginator(feedSession(), "SELECT * FROM posts",
+		core.PartitionValues("user_id", "a", "b"),
+		core.MergeOrder{Column: "ts"},
+		core.Options{PageSize: 2},
+	)
+
+	_, token, err := m.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other := core.NewMultiPartitionPaginator(feedSession(), "SELECT * FROM posts",
+		core.PartitionValues("user_id", "a", "b", "c"),
+		core.MergeOrder{Column: "ts"},
+		core.Options{PageSize: 2},
+	)
+	if _, _, err := other.NextWithToken(token); err == nil {
+		t.Fatal("expected error for token with a different partition count")
+	}
+}
+
+func TestMultiPartitionPaginator_PerCallContext(t *testing.T) {
+	m := core.NewMultiPartitionPaginator(feedSession(), "SELECT * FROM posts",
+		core.PartitionValues("user_id", "a", "b", "c"),
+		core.MergeOrder{Column: "ts"},
+		core.Options{PageSize: 4},
+	)
+
+	rows, token, err := m.NextContext(context.Background())
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d, err %v", len(rows), err)
+	}
+	_, next, err := m.NextWithTokenContext(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := m.NextWithTokenContext(ctx, token); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from NextWithTokenContext, got %v", err)
+	}
+	if _, _, err := m.PreviousContext(ctx, next); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from PreviousContext, got %v", err)
+	}
+
+	// Options.Context is not consulted by the per-call variants
+	m.Opts.Context = ctx
+	if rows, _, err := m.PreviousContext(context.Background(), next); err != nil || !reflect.DeepEqual(timestamps(rows), []int{5, 6, 7, 8}) {
+		t.Errorf("expected the second page, got %v (%v)", timestamps(rows), err)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 // Options holds configuration for the paginator.
@@ -12,4 +13,56 @@ type Options struct {
 	Context  context.Context
 	Logger   func(event string, data map[string]interface{})
 	Metrics  MetricsCollector // optional metrics hook
+
+	Keys      *TableKeys      // optional key metadata used to analyse filters
+	Filtering FilteringPolicy // ALLOW FILTERING handling (default: FilteringManual)
+	Schema    SchemaLoader    // optional schema source used for validation and key metadata
+
+	PageIndex   PageIndex // optional cache of page boundaries used by GoToPage
+	MaxPageWalk int       // max pages GoToPage walks (default: DefaultMaxPageWalk)
+
+	Keyset bool // page by clustering-column cursors; enables Previous from any page and Last
+
+	PrefetchDepth int // pages Pages fetches ahead in the background (default: 0, synchronous)
+
+	Adaptive     *AdaptiveFetch // optional driver fetch size tuning; pages keep PageSize rows
+	MaxPageBytes int            // end a page early once its rows reach about this many bytes (default: no limit)
+	LookAhead    bool           // peek one row past each page so HasMore is exact and the last page has no next token
+
+	Retry     *RetryPolicy // optional retries of transient query failures (default: none)
+	RateLimit *RateLimiter // optional throttle, may be shared by many paginators
+
+	PageTimeout time.Duration // bound on each page fetch, within the context deadline (default: none)
+
+	QueryConfig *QueryConfig // optional consistency, idempotency and driver settings for every query
+
+	TokenRange *TokenRange // optional partition token range to scan, e.g. one shard's slice of the ring
+
+	Diagnostics *Diagnostics // optional per-page diagnostics for sampled pages (default: off)
+}
+
+// context returns Context, or context.Background when none is set.
+func (o *Options) context() context.Context {
+	if o.Context != nil {
+		return o.Context
+	}
+	return context.Background()
+}
+
+// log safely invokes the optional logger hook.
+func (o *Options) log(event string, data map[string]interface{}) {
+	if o.Logger != nil {
+		o.Logger(event, data)
+	}
+}
+
+// observeInvalidToken logs and counts a token that failed to decode.
+func (o *Options) observeInvalidToken(token string, err error) {
+	o.log("invalid_token", map[string]interface{}{
+		"token": token,
+		"error": err.Error(),
+	})
+	if o.Metrics != nil {
+		o.Metrics.ObserveError(ErrInvalidToken)
+	}
 }
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// PageInfo describes a fetched page: the tokens around it and how it was fetched.
+type PageInfo struct {
+	NextToken string // token of the following page, "" once HasMore is false
+	PrevToken string // token of the preceding page, "" on the first page or when unknown
+	HasMore   bool   // whether rows may follow the page
+
+	Duration           time.Duration    // time to fetch the page, including retries
+	Attempts           int              // attempts made, more than 1 with Options.Retry
+	FetchedDriverPages int              // driver pages the rows were read from
+	Warnings           []string         // server warnings of every driver page read, from iterators implementing WarningIter
+	Diagnostics        *PageDiagnostics // set when the page was sampled by Options.Diagnostics
+}
+
+// Page is a page of rows together with its PageInfo.
+type Page[T any] struct {
+	Rows []T
+	PageInfo
+}
+
+// startToken is a non-empty token for the first page. Pages reached from it
+// link back to the first page, which an empty token cannot express.
+var startToken = encodeJSONToken(struct{}{})
+
+// FetchPage returns the page at token ("" for the first page). Both NextToken
+// and PrevToken of the result can be passed back to FetchPage; keyset pages
+// get a PrevToken that reads the rows before them.
+func (p *Paginator) FetchPage(ctx context.Context, token string) (Page[map[string]interface{}], error) {
+	if token == "" {
+		token = startToken
+	}
+	results, info, err := p.NextWithTokenInfo(ctx, token)
+	if err != nil {
+		return Page[map[string]interface{}]{}, err
+	}
+	return Page[map[string]interface{}]{Rows: results, PageInfo: info}, nil
+}
+
+// NextWithTokenInfo is NextWithTokenContext returning the next token inside a
+// PageInfo, together with how the page was fetched.
+func (p *Paginator) NextWithTokenInfo(ctx context.Context, token string) ([]map[string]interface{}, PageInfo, error) {
+	results, info, err := p.fetchWithToken(ctx, token)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	if !info.HasMore {
+		info.NextToken = ""
+	}
+	return results, info, nil
+}
+
+// isStart reports whether the envelope points at the first page.
+func (e *TokenEnvelope) isStart() bool {
+	return len(e.State) == 0 && e.Skip == 0 && len(e.First) == 0 && len(e.Last) == 0
+}
@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// blobRows builds rows whose payload sizes follow sizes, cycling.
+func blobRows(n int, sizes ...int) []map[string]interface{} {
+	rows := numberedRows(n)
+	for i, row := range rows {
+		row["payload"] = strings.Repeat("x", sizes[i%len(sizes)])
+	}
+	return rows
+}
+
+func TestMaxPageBytes_CutsPagesAndResumesExactly(t *testing.T) {
+	rows := blobRows(40, 100, 900, 100, 5000)
+	p := core.NewPaginator(newTableSession("blobs", rows), "SELECT * FROM blobs", core.Options{
+		PageSize:     10,
+		MaxPageBytes: 2000,
+	})
+
+	var ids []int
+	token := ""
+	for i := 0; i < 100; i++ {
+		page, next, err := p.NextWithToken(token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		env, _ := core.DecodeToken(next)
+		last := len(env.State) == 0 && env.Skip == 0
+		if len(page) == 0 {
+			// Like Cassandra, a driver page ending on the last row is
+			// followed by an empty one
+			if !last {
+				t.Fatalf("page %d is empty", i)
+			}
+			break
+		}
+
+		// Everything but the last row must fit the budget
+		size := 0
+		for _, row := range page[:len(page)-1] {
+			size += len(row["payload"].(string))
+		}
+		if size >= 2000 {
+			t.Errorf("page %d holds %d payload bytes before its last row", i, size)
+		}
+
+		ids = append(ids, rowIDs(page)...)
+		if last {
+			break
+		}
+		token = next
+	}
+
+	assertSequence(t, ids, 40)
+}
+
+func TestMaxPageBytes_OversizedRowEndsPage(t *testing.T) {
+	rows := blobRows(3, 10, 10000, 10)
+	p := core.NewPaginator(newTableSession("blobs", rows), "SELECT * FROM blobs", core.Options{
+		PageSize:     10,
+		MaxPageBytes: 1000,
+	})
+
+	it := p.Pages("")
+	defer it.Close()
+	var lengths []int
+	for it.Next() {
+		lengths = append(lengths, len(it.Rows()))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 10 + 10000 bytes cross the budget at row 1; row 2 fits alone
+	if len(lengths) != 2 || lengths[0] != 2 || lengths[1] != 1 {
+		t.Errorf("unexpected page lengths: %v", lengths)
+	}
+}
+
+func TestMaxPageBytes_WithoutLimitKeepsPageSize(t *testing.T) {
+	p := core.NewPaginator(newTableSession("blobs", blobRows(25, 5000)), "SELECT * FROM blobs", core.Options{PageSize: 10})
+
+	rows, _, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 10 {
+		t.Errorf("expected 10 rows, got %d", len(rows))
+	}
+}
+
+func TestMaxPageBytes_ShrinksFetchSize(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("blobs", blobRows(30, 1000))
+	p := core.NewPaginator(s, "SELECT * FROM blobs", core.Options{
+		PageSize:     20,
+		MaxPageBytes: 3000,
+	})
+
+	var ids []int
+	it := p.Pages("")
+	defer it.Close()
+	for it.Next() {
+		ids = append(ids, rowIDs(it.Rows())...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertSequence(t, ids, 30)
+
+	// Once the row width is known the driver is asked for about a budget of
+	// rows (3 rows of ~1 KB), not PageSize
+	queries := s.Queries()
+	if queries[0].PageSize != 20 {
+		t.Errorf("expected the first fetch to use PageSize, got %d", queries[0].PageSize)
+	}
+	for _, q := range queries[1:] {
+		if q.PageSize != 3 {
+			t.Errorf("expected a fetch size of 3, got %d", q.PageSize)
+		}
+	}
+	if len(queries) > 11 {
+		t.Errorf("expected about one query per page, got %d", len(queries))
+	}
+}
@@ -0,0 +1,303 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// numberedRows builds n rows with an increasing "id" column.
+func numberedRows(n int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i}
+	}
+	return rows
+}
+
+// newTableSession returns a session holding rows in table.
+func newTableSession(table string, rows []map[string]interface{}) *caspagetest.Session {
+	s := caspagetest.NewSession()
+	s.AddTable(table, rows)
+	return s
+}
+
+func ids(rows []map[string]interface{}) []int {
+	out := make([]int, len(rows))
+	for i, r := range rows {
+		out[i] = r["id"].(int)
+	}
+	return out
+}
+
+func TestFetchPage_Tokens(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(12))
+	s.InjectWarning("FROM users", "Read 5 live rows and 3000 tombstone cells")
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{
+		PageSize: 5,
+		Adaptive: &core.AdaptiveFetch{MinFetchSize: 2, MaxFetchSize: 2},
+	})
+	ctx := context.Background()
+
+	first, err := p.FetchPage(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(first.Rows), []int{0, 1, 2, 3, 4}) || !first.HasMore || first.NextToken == "" || first.PrevToken != "" {
+		t.Fatalf("unexpected first page %v %+v", ids(first.Rows), first.PageInfo)
+	}
+	if first.FetchedDriverPages != 3 || first.Attempts != 1 || first.Duration <= 0 || len(first.Warnings) != 1 {
+		t.Errorf("unexpected page info %+v", first.PageInfo)
+	}
+
+	second, err := p.FetchPage(ctx, first.NextToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(second.Rows), []int{5, 6, 7, 8, 9}) || second.PrevToken == "" {
+		t.Fatalf("unexpected second page %v %+v", ids(second.Rows), second.PageInfo)
+	}
+
+	last, err := p.FetchPage(ctx, second.NextToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(last.Rows), []int{10, 11}) || last.HasMore || last.NextToken != "" {
+		t.Fatalf("unexpected last page %v %+v", ids(last.Rows), last.PageInfo)
+	}
+
+	// PrevToken leads back page by page, to the first page
+	back, err := p.FetchPage(ctx, last.PrevToken)
+	if err != nil || !reflect.DeepEqual(ids(back.Rows), []int{5, 6, 7, 8, 9}) {
+		t.Fatalf("expected the second page, got %v (%v)", ids(back.Rows), err)
+	}
+	back, err = p.FetchPage(ctx, back.PrevToken)
+	if err != nil || !reflect.DeepEqual(ids(back.Rows), []int{0, 1, 2, 3, 4}) || back.PrevToken != "" {
+		t.Fatalf("expected the first page, got %v %+v (%v)", ids(back.Rows), back.PageInfo, err)
+	}
+}
+
+func TestFetchPage_Keyset(t *testing.T) {
+	p := core.NewPaginator(timelineSession(12), "SELECT * FROM messages", core.Options{
+		PageSize: 5,
+		Keyset:   true,
+		Keys:     &core.TableKeys{PartitionKeys: []string{"room"}, ClusteringKeys: []string{"ts"}},
+	})
+	ctx := context.Background()
+
+	first, err := p.FetchPage(ctx, "")
+	if err != nil || first.PrevToken != "" || !first.HasMore {
+		t.Fatalf("unexpected first page %+v (%v)", first.PageInfo, err)
+	}
+	second, err := p.FetchPage(ctx, first.NextToken)
+	if err != nil || !reflect.DeepEqual(timestamps(second.Rows), []int{5, 6, 7, 8, 9}) {
+		t.Fatalf("unexpected second page %v (%v)", timestamps(second.Rows), err)
+	}
+	last, err := p.FetchPage(ctx, second.NextToken)
+	if err != nil || last.HasMore || last.NextToken != "" {
+		t.Fatalf("unexpected last page %v %+v (%v)", timestamps(last.Rows), last.PageInfo, err)
+	}
+
+	// The keyset PrevToken reads the rows before the page, forward
+	back, err := p.FetchPage(ctx, second.PrevToken)
+	if err != nil || !reflect.DeepEqual(timestamps(back.Rows), []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("expected the first page, got %v (%v)", timestamps(back.Rows), err)
+	}
+	if !back.HasMore || back.NextToken == "" {
+		t.Errorf("unexpected page info %+v", back.PageInfo)
+	}
+}
+
+func TestFetchPageAs(t *testing.T) {
+	type user struct {
+		ID int `cql:"id"`
+	}
+	p := core.NewPaginator(newTableSession("users", numberedRows(3)), "SELECT * FROM users", core.Options{PageSize: 5})
+
+	page, err := core.FetchPageAs[user](context.Background(), p, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Rows) != 3 || page.Rows[2].ID != 2 || page.HasMore || page.NextToken != "" {
+		t.Errorf("unexpected page %+v", page)
+	}
+}
+
+func TestLookAhead_ExactlyFullLastPage(t *testing.T) {
+	for _, lookAhead := range []bool{false, true} {
+		s := caspagetest.NewSession()
+		s.AddTable("users", numberedRows(10))
+		p := core.NewPaginator(s, "SELECT * FROM users", core.Options{
+			PageSize:  5,
+			Adaptive:  &core.AdaptiveFetch{MinFetchSize: 5, MaxFetchSize: 5},
+			LookAhead: lookAhead,
+		})
+
+		_, token, err := p.NextWithToken("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows, token, err := p.NextWithToken(token)
+		if err != nil || len(rows) != 5 {
+			t.Fatalf("unexpected second page: %d rows (%v)", len(rows), err)
+		}
+		if lookAhead {
+			if token != "" {
+				t.Errorf("expected no next token with LookAhead, got %q", token)
+			}
+			continue
+		}
+
+		// Without LookAhead the driver's page state leads to one more, empty page
+		if token == "" {
+			t.Fatal("expected a next token without LookAhead")
+		}
+		rows, _, err = p.NextWithToken(token)
+		if err != nil || len(rows) != 0 {
+			t.Errorf("expected an empty page, got %d rows (%v)", len(rows), err)
+		}
+	}
+}
+
+func TestLookAhead_InsideDriverPage(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(12))
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{
+		PageSize:  4,
+		Adaptive:  &core.AdaptiveFetch{MinFetchSize: 3, MaxFetchSize: 3},
+		LookAhead: true,
+	})
+
+	var got []int
+	var infos []core.PageInfo
+	it := p.Pages("")
+	defer it.Close()
+	for it.Next() {
+		got = append(got, ids(it.Rows())...)
+		infos = append(infos, it.Info())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}) {
+		t.Fatalf("unexpected rows %v", got)
+	}
+	if len(infos) != 3 || !infos[1].HasMore || infos[2].HasMore || infos[2].NextToken != "" {
+		t.Errorf("unexpected pages %+v", infos)
+	}
+}
+
+func TestLookAhead_Keyset(t *testing.T) {
+	p := core.NewPaginator(timelineSession(10), "SELECT * FROM messages", core.Options{
+		PageSize:  5,
+		Keyset:    true,
+		Keys:      &core.TableKeys{PartitionKeys: []string{"room"}, ClusteringKeys: []string{"ts"}},
+		LookAhead: true,
+	})
+	ctx := context.Background()
+
+	first, err := p.FetchPage(ctx, "")
+	if err != nil || !first.HasMore {
+		t.Fatalf("unexpected first page %+v (%v)", first.PageInfo, err)
+	}
+	second, err := p.FetchPage(ctx, first.NextToken)
+	if err != nil || !reflect.DeepEqual(timestamps(second.Rows), []int{5, 6, 7, 8, 9}) {
+		t.Fatalf("unexpected second page %v (%v)", timestamps(second.Rows), err)
+	}
+	if second.HasMore || second.NextToken != "" {
+		t.Errorf("expected the last page, got %+v", second.PageInfo)
+	}
+
+	// Looking ahead backward also finds the start
+	back, err := p.FetchPage(ctx, second.PrevToken)
+	if err != nil || !reflect.DeepEqual(timestamps(back.Rows), []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("expected the first page, got %v (%v)", timestamps(back.Rows), err)
+	}
+	if back.PrevToken != "" || !back.HasMore {
+		t.Errorf("unexpected page info %+v", back.PageInfo)
+	}
+}
+
+func TestLookAhead_ReadsEachDriverPageOnce(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(20))
+	counter := &fetchCounter{CassandraSession: s}
+	p := core.NewPaginator(counter, "SELECT * FROM users", core.Options{PageSize: 5, LookAhead: true})
+
+	var got []int
+	it := p.Pages("")
+	defer it.Close()
+	for it.Next() {
+		got = append(got, ids(it.Rows())...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 20 || it.Info().HasMore {
+		t.Fatalf("unexpected rows %v %+v", got, it.Info())
+	}
+
+	// Looking ahead probes a single row past each page rather than fetching the
+	// next driver page, which the following page would then read again
+	if counter.rows > 20+4 {
+		t.Errorf("expected about 20 rows requested from the driver, got %d", counter.rows)
+	}
+}
+
+// fetchCounter counts the rows requested from the driver, one fetch size for
+// every driver page an iterator fetches.
+type fetchCounter struct {
+	core.CassandraSession
+	rows int
+}
+
+func (s *fetchCounter) Query(stmt string, args ...interface{}) core.CassandraQuery {
+	return &countedQuery{CassandraQuery: s.CassandraSession.Query(stmt, args...), counter: s}
+}
+
+type countedQuery struct {
+	core.CassandraQuery
+	counter  *fetchCounter
+	pageSize int
+}
+
+func (q *countedQuery) PageSize(n int) core.CassandraQuery {
+	q.CassandraQuery, q.pageSize = q.CassandraQuery.PageSize(n), n
+	return q
+}
+
+func (q *countedQuery) PageState(b []byte) core.CassandraQuery {
+	q.CassandraQuery = q.CassandraQuery.PageState(b)
+	return q
+}
+
+func (q *countedQuery) WithContext(ctx context.Context) core.CassandraQuery {
+	q.CassandraQuery = q.CassandraQuery.WithContext(ctx)
+	return q
+}
+
+func (q *countedQuery) Iter() core.CassandraIter {
+	q.counter.rows += q.pageSize
+	iter := q.CassandraQuery.Iter()
+	return &countedIter{CassandraIter: iter, query: q, state: iter.PageState()}
+}
+
+type countedIter struct {
+	core.CassandraIter
+	query *countedQuery
+	state []byte
+}
+
+func (i *countedIter) MapScan(m map[string]interface{}) bool {
+	ok := i.CassandraIter.MapScan(m)
+	if state := i.CassandraIter.PageState(); !bytes.Equal(state, i.state) {
+		i.query.counter.rows += i.query.pageSize
+		i.state = state
+	}
+	return ok
+}
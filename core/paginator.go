@@ -1,8 +1,12 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -11,6 +15,17 @@ type Paginator struct {
 	Query    string
 	PageSize int
 	Opts     Options
+
+	// lazily loaded table schema (see Schema)
+	schemaMu sync.Mutex
+	schema   *TableSchema
+
+	// driver fetch size state (see Options.Adaptive)
+	sizer fetchSizer
+
+	// paginator of the Definition this one was derived from, whose schema and
+	// fetch size state are used instead
+	parent *Paginator
 }
 
 // NewPaginator now initializes a cache too
@@ -29,16 +44,25 @@ func NewPaginator(session CassandraSession, query string, Opts Options) *Paginat
 }
 
 func (p *Paginator) NextWithToken(token string) ([]map[string]interface{}, string, error) {
-	results, nextToken, err := p.fetchWithToken(token)
+	return p.NextWithTokenContext(p.Opts.context(), token)
+}
+
+// NextWithTokenContext is NextWithToken with a per-call context, used instead of Options.Context.
+func (p *Paginator) NextWithTokenContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	results, info, err := p.fetchWithToken(ctx, token)
 	if err != nil {
 		return nil, "", err
 	}
 
-	return results, nextToken, nil
+	// With LookAhead the end is known, so the last page gets no next token
+	if p.Opts.LookAhead && !info.HasMore {
+		return results, "", nil
+	}
+	return results, info.NextToken, nil
 }
 
 // fetchWithToken executes the paginated Cassandra query and returns results and the next page token.
-func (p *Paginator) fetchWithToken(token string) ([]map[string]interface{}, string, error) {
+func (p *Paginator) fetchWithToken(ctx context.Context, token string) ([]map[string]interface{}, PageInfo, error) {
 	var env *TokenEnvelope
 	var err error
 	var prev string
@@ -47,102 +71,348 @@ func (p *Paginator) fetchWithToken(token string) ([]map[string]interface{}, stri
 	if token != "" {
 		env, err = DecodeToken(token)
 		if err != nil {
-			p.log("invalid_token", map[string]interface{}{
-				"token": token,
-				"error": err.Error(),
-			})
-			if p.Opts.Metrics != nil {
-				p.Opts.Metrics.ObserveError(ErrInvalidToken)
-			}
-			return nil, "", ErrInvalidToken
+			p.Opts.observeInvalidToken(token, err)
+			return nil, PageInfo{}, ErrInvalidToken
 		}
 		prev = token // current token becomes "prev" for the next page
 	} else {
 		env = &TokenEnvelope{}
 	}
 
+	// Keyset tokens (and Options.Keyset) page by clustering cursors instead;
+	// tokens with only a first cursor read the rows before it
+	if p.isKeyset(env) {
+		return p.fetchKeyset(ctx, env, len(env.First) > 0 && len(env.Last) == 0)
+	}
+
 	// 2️⃣ Build the query string dynamically (columns + filters)
-	queryStr := p.Query
+	queryStr, bindValues, err := p.buildQuery(p.Query, queryParts{columns: p.Opts.Columns})
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
 
-	// Replace "*" with selected columns if provided
-	if len(p.Opts.Columns) > 0 {
-		queryStr = strings.Replace(queryStr, "*", strings.Join(p.Opts.Columns, ", "), 1)
+	// 3️⃣ Fetch the page, resuming from the token's page state
+	page, err := p.fetchPage(ctx, queryStr, bindValues, env.State, env.Skip)
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
 
-	// Use helper to build WHERE/AND clauses dynamically
-	queryStr, bindValues := buildQueryWithFilters(queryStr, p.Opts.Filters)
+	// 4️⃣ Encode next token with embedded "prev"
+	next := TokenEnvelope{State: page.nextState, Skip: page.nextSkip, Prev: prev}
 
-	// Initialize query with optional bound values
-	q := p.Session.Query(queryStr, bindValues...).PageSize(p.PageSize)
+	info := page.info(next.Encode())
+	info.HasMore = page.hasMore()
+	if !env.isStart() {
+		info.PrevToken = env.Prev
+	}
+	return page.rows, info, nil
+}
+
+// fetchPage runs one page of an already-built query starting at the given page state,
+// dropping the first skip rows. The page's nextState and nextSkip are the position right
+// after the page: a page state plus the rows of that driver page already returned; both
+// are empty at the end.
+func (p *Paginator) fetchPage(ctx context.Context, queryStr string, bindValues []interface{}, state []byte, skip int) (pageScan, error) {
+	start := time.Now()
+	diagnostics := p.sampleDiagnostics()
+
+	// 1️⃣ Scan the page, retrying transient failures from the same position;
+	// every attempt waits for the rate limiter, if any
+	var page pageScan
+	var limitErr error
+	expected := p.PageSize + skip
+	attempts, err := p.retry(ctx, queryStr, func() error {
+		if limitErr = p.throttle(ctx, expected); limitErr != nil {
+			return limitErr
+		}
+		return p.withPageTimeout(ctx, func(ctx context.Context) error {
+			var err error
+			page, err = p.scanPage(ctx, queryStr, bindValues, state, skip, diagnostics)
+			return err
+		})
+	})
+	duration := time.Since(start)
+	if limitErr != nil {
+		return pageScan{}, limitErr
+	}
+	if diagnostics != nil {
+		diagnostics.Attempts, diagnostics.Latency = attempts, duration
+		p.observeDiagnostics(queryStr, diagnostics)
+	}
+
+	// 2️⃣ Handle query errors
+	if err != nil {
+		return pageScan{}, p.queryFailed(queryStr, err, attempts, map[string]interface{}{
+			"duration":  duration.Milliseconds(),
+			"page_size": p.PageSize,
+		})
+	}
+	page.attempts, page.latency, page.diagnostics = attempts, duration, diagnostics
 
-	// 3️⃣ Apply page state if resuming from token
-	if len(env.State) > 0 {
-		q = q.PageState(env.State)
+	// 3️⃣ Log success
+	fetched := map[string]interface{}{
+		"rows_fetched":  len(page.rows),
+		"next_token":    len(page.nextState) > 0 || page.nextSkip > 0,
+		"duration_ms":   duration.Milliseconds(),
+		"query_filters": p.Opts.Filters,
+	}
+	if p.Opts.MaxPageBytes > 0 {
+		fetched["page_bytes"] = page.bytes
 	}
+	if attempts > 1 {
+		fetched["attempts"] = attempts
+	}
+	p.Opts.log("page_fetched", fetched)
 
-	// 4️⃣ Apply context if present (for timeout/cancellation)
-	if p.Opts.Context != nil {
-		q = q.WithContext(p.Opts.Context)
+	// 4️⃣ Record metrics, settle the rate limiter and tune the fetch size
+	if p.Opts.Metrics != nil {
+		p.Opts.Metrics.ObservePageFetch(len(page.rows), duration)
 	}
+	p.settle(expected, page.scanned)
+	p.adaptFetchSize(page.rows, page.scanned, page.duration)
+
+	return page, nil
+}
+
+// pageScan is the outcome of one attempt at reading a page.
+type pageScan struct {
+	rows      []map[string]interface{}
+	nextState []byte
+	nextSkip  int
+	scanned   int // rows read, including skipped ones
+	bytes     int // estimated size of rows, tracked with MaxPageBytes
+	duration  time.Duration
+
+	driverPages int      // driver pages the rows were read from
+	exhausted   bool     // no rows follow the page; always known with Options.LookAhead
+	warnings    []string // server warnings, from iterators implementing WarningIter
+
+	// set by fetchPage once the page is read
+	attempts    int
+	latency     time.Duration
+	diagnostics *PageDiagnostics
+}
+
+// hasMore reports whether a page may follow: the scan was not exhausted and
+// left a position to resume from.
+func (page pageScan) hasMore() bool {
+	return !page.exhausted && (len(page.nextState) > 0 || page.nextSkip > 0)
+}
+
+// info describes the page for PageInfo.
+func (page pageScan) info(nextToken string) PageInfo {
+	return PageInfo{
+		NextToken:          nextToken,
+		Duration:           page.latency,
+		Attempts:           page.attempts,
+		FetchedDriverPages: page.driverPages,
+		Warnings:           page.warnings,
+		Diagnostics:        page.diagnostics,
+	}
+}
+
+// scanPage runs the query once and reads one page from the given position.
+func (p *Paginator) scanPage(ctx context.Context, queryStr string, bindValues []interface{}, state []byte, skip int, diagnostics *PageDiagnostics) (pageScan, error) {
+	// Initialize query with optional bound values
+	fetchSize := p.fetchSize()
+	q := p.Session.Query(queryStr, bindValues...).PageSize(fetchSize)
+	q = p.configure(q)
+	q = p.diagnose(q, diagnostics)
+
+	// Apply page state if resuming from token
+	if len(state) > 0 {
+		q = q.PageState(state)
+	}
+
+	// Apply context (for timeout/cancellation)
+	q = q.WithContext(ctx)
 
 	start := time.Now()
 	iter := q.Iter()
 
-	results := []map[string]interface{}{}
+	page := pageScan{rows: []map[string]interface{}{}, driverPages: 1}
 	row := map[string]interface{}{}
-	count := 0
+
+	// The driver may fetch several pages (or stop inside one) to fill the page,
+	// so track the driver page being read and how many of its rows were consumed.
+	pageStart, current := state, iter.PageState()
+	consumed := 0
+	stopped := false
 
 	for iter.MapScan(row) {
-		results = append(results, row)
+		if s := iter.PageState(); !bytes.Equal(s, current) {
+			pageStart, current, consumed = current, s, 0
+			page.driverPages++
+		}
+		consumed++
+		page.scanned++
+		if page.scanned <= skip {
+			row = map[string]interface{}{}
+			continue
+		}
+
+		page.rows = append(page.rows, row)
+		if p.Opts.MaxPageBytes > 0 {
+			page.bytes += rowBytes(row)
+		}
 		row = map[string]interface{}{}
-		count++
-		if count >= p.PageSize {
+		if len(page.rows) >= p.PageSize || (p.Opts.MaxPageBytes > 0 && page.bytes >= p.Opts.MaxPageBytes) {
+			stopped = true
 			break
 		}
 	}
 
-	duration := time.Since(start)
-	nextState := iter.PageState()
+	// Resume after the driver page when it is used up, otherwise inside it
+	page.nextState = iter.PageState()
+	switch {
+	case !stopped:
+		page.exhausted = true
+	case p.Opts.LookAhead && consumed < fetchSize:
+		// Peek one row of the driver page, so an exactly full last page ends
+		// here instead of one empty page later
+		if !iter.MapScan(map[string]interface{}{}) {
+			page.nextState, page.exhausted = nil, true
+		} else if bytes.Equal(iter.PageState(), current) {
+			page.nextState, page.nextSkip = pageStart, consumed
+		}
+	case p.Opts.LookAhead:
+		// The page ends with its driver page; probed below once iter is closed
+	case consumed < fetchSize:
+		if len(page.nextState) > 0 || iter.MapScan(map[string]interface{}{}) {
+			page.nextState, page.nextSkip = pageStart, consumed
+		}
+	}
 
-	// 5️⃣ Handle query errors
+	page.duration = time.Since(start)
+	if w, ok := iter.(WarningIter); ok {
+		page.warnings = w.Warnings()
+	}
 	if err := iter.Close(); err != nil {
-		p.log("query_failed", map[string]interface{}{
-			"query":     queryStr,
-			"error":     err.Error(),
-			"filters":   p.Opts.Filters,
-			"duration":  duration.Milliseconds(),
-			"page_size": p.PageSize,
+		return pageScan{}, err
+	}
+
+	// Probe the next driver page for a single row rather than fetching (and
+	// discarding) all of it; the next page then resumes right at its start
+	if p.Opts.LookAhead && stopped && consumed >= fetchSize {
+		more, err := p.probe(ctx, queryStr, bindValues, page.nextState)
+		if err != nil {
+			return pageScan{}, err
+		}
+		if !more {
+			page.nextState, page.exhausted = nil, true
+		}
+	}
+	return page, nil
+}
+
+// probe reports whether the query has a row at the given page state, reading
+// at most one.
+func (p *Paginator) probe(ctx context.Context, queryStr string, bindValues []interface{}, state []byte) (bool, error) {
+	if len(state) == 0 {
+		return false, nil
+	}
+	q := p.configure(p.Session.Query(queryStr, bindValues...).PageSize(1))
+	iter := q.PageState(state).WithContext(ctx).Iter()
+	more := iter.MapScan(map[string]interface{}{})
+	if err := iter.Close(); err != nil {
+		return false, err
+	}
+	return more, nil
+}
+
+// queryParts are the pieces buildQuery adds to the base query.
+type queryParts struct {
+	columns   []string      // replaces "*" when set
+	relations []string      // extra WHERE relations appended after the filters
+	values    []interface{} // values bound to relations
+	orderBy   string        // ORDER BY clause, without the keywords
+
+	tokenRange *TokenRange // partition token restriction, replacing Options.TokenRange
+}
+
+// buildQuery validates the options against the schema, substitutes the selected columns,
+// appends the filters and extra relations and applies the ALLOW FILTERING policy.
+func (p *Paginator) buildQuery(base string, parts queryParts) (string, []interface{}, error) {
+	// Validate columns and filters when a schema loader is configured
+	if err := p.validateAgainstSchema(); err != nil {
+		p.Opts.log("schema_validation_failed", map[string]interface{}{
+			"query":   p.Query,
+			"error":   err.Error(),
+			"filters": p.Opts.Filters,
+			"columns": p.Opts.Columns,
 		})
 		if p.Opts.Metrics != nil {
-			p.Opts.Metrics.ObserveError(ErrQueryFailed)
+			p.Opts.Metrics.ObserveError(err)
 		}
-		return nil, "", ErrQueryFailed
+		return "", nil, err
 	}
 
-	// 6️⃣ Log success
-	p.log("page_fetched", map[string]interface{}{
-		"rows_fetched":  len(results),
-		"next_token":    len(nextState) > 0,
-		"duration_ms":   duration.Milliseconds(),
-		"query_filters": p.Opts.Filters,
-	})
+	queryStr := base
 
-	// 7️⃣ Record metrics
-	if p.Opts.Metrics != nil {
-		p.Opts.Metrics.ObservePageFetch(len(results), duration)
+	// Replace "*" with selected columns if provided
+	if len(parts.columns) > 0 {
+		queryStr = strings.Replace(queryStr, "*", strings.Join(parts.columns, ", "), 1)
 	}
 
-	// 8️⃣ Encode next token with embedded "prev"
-	nextToken := EncodeToken(nextState, prev)
+	// Use helper to build WHERE/AND clauses dynamically
+	queryStr, bindValues := buildQueryWithFilters(queryStr, p.Opts.Filters)
+
+	// Restrict the partition token to the scanned range
+	tokenRange := parts.tokenRange
+	if tokenRange == nil {
+		tokenRange = p.Opts.TokenRange
+	}
+	if tokenRange != nil {
+		keys := p.keys()
+		if keys == nil || len(keys.PartitionKeys) == 0 {
+			return "", nil, fmt.Errorf("%w: no partition keys known", ErrTokenRangeUnavailable)
+		}
+		relation, values := tokenRange.relation(keys.PartitionKeys)
+		queryStr = appendRelations(queryStr, []string{relation})
+		bindValues = append(bindValues, values...)
+	}
+
+	// Extra relations (token ranges, keyset bounds, ...) go after the filters
+	if len(parts.relations) > 0 {
+		queryStr = appendRelations(queryStr, parts.relations)
+		bindValues = append(bindValues, parts.values...)
+	}
+	if parts.orderBy != "" {
+		queryStr += " ORDER BY " + parts.orderBy
+	}
+
+	// Append or refuse ALLOW FILTERING depending on the configured policy
+	filteredQuery, err := p.applyFilteringPolicy(queryStr)
+	if err != nil {
+		p.Opts.log("filtering_refused", map[string]interface{}{
+			"query":   queryStr,
+			"error":   err.Error(),
+			"filters": p.Opts.Filters,
+		})
+		if p.Opts.Metrics != nil {
+			p.Opts.Metrics.ObserveError(ErrFilteringRequired)
+		}
+		return "", nil, err
+	}
 
-	return results, nextToken, nil
+	return filteredQuery, bindValues, nil
 }
 
-// log safely invokes the optional logger hook.
-func (p *Paginator) log(event string, data map[string]interface{}) {
-	if p.Opts.Logger != nil {
-		p.Opts.Logger(event, data)
+// withPageTimeout runs one page fetch under Options.PageTimeout. The caller's
+// deadline still applies when it is sooner; a fetch cut short by the page
+// timeout alone fails with ErrPageTimeout.
+func (p *Paginator) withPageTimeout(ctx context.Context, fetch func(context.Context) error) error {
+	if p.Opts.PageTimeout <= 0 {
+		return fetch(ctx)
 	}
+	pageCtx, cancel := context.WithTimeout(ctx, p.Opts.PageTimeout)
+	defer cancel()
+
+	err := fetch(pageCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(pageCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %v: %w", ErrPageTimeout, p.Opts.PageTimeout, err)
+	}
+	return err
 }
 
 // Stateful convenience wrapper (calls the stateless version internally)
@@ -151,18 +421,42 @@ func (p *Paginator) Next() ([]map[string]interface{}, string, error) {
 	return p.NextWithToken("")
 }
 
+// NextContext is Next with a per-call context, used instead of Options.Context.
+func (p *Paginator) NextContext(ctx context.Context) ([]map[string]interface{}, string, error) {
+	return p.NextWithTokenContext(ctx, "")
+}
+
 // Previous navigates one page backward using the embedded "prev" token.
 // It decodes the given token, extracts the previous token inside it, and fetches that page.
 func (p *Paginator) Previous(token string) ([]map[string]interface{}, string, error) {
-	env, err := DecodeToken(token)
+	return p.PreviousContext(p.Opts.context(), token)
+}
+
+// PreviousContext is Previous with a per-call context, used instead of Options.Context.
+func (p *Paginator) PreviousContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	results, info, err := p.previous(ctx, token)
 	if err != nil {
 		return nil, "", err
 	}
+	return results, info.NextToken, nil
+}
+
+// previous fetches the page before the token's page.
+func (p *Paginator) previous(ctx context.Context, token string) ([]map[string]interface{}, PageInfo, error) {
+	env, err := DecodeToken(token)
+	if err != nil {
+		return nil, PageInfo{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// Keyset tokens read the rows before the page in reverse clustering order
+	if len(env.First) > 0 {
+		return p.fetchKeyset(ctx, env, true)
+	}
 
 	if env.Prev == "" {
-		return nil, "", fmt.Errorf("no previous page available")
+		return nil, PageInfo{}, ErrNoPrevToken
 	}
 
 	// Directly fetch the previous page using the embedded previous token.
-	return p.fetchWithToken(env.Prev)
+	return p.fetchWithToken(ctx, env.Prev)
 }
@@ -1,6 +1,7 @@
 package core_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/AnukritiSharma1609/caspage/core"
@@ -16,10 +17,10 @@ func (m *mockSession) Query(q string, args ...interface{}) core.CassandraQuery {
 
 type mockQuery struct{}
 
-func (q *mockQuery) PageSize(n int) core.CassandraQuery              { return q }
-func (q *mockQuery) PageState(b []byte) core.CassandraQuery          { return q }
-func (q *mockQuery) WithContext(ctx interface{}) core.CassandraQuery { return q }
-func (q *mockQuery) Iter() core.CassandraIter                        { return &mockIter{} }
+func (q *mockQuery) PageSize(n int) core.CassandraQuery                  { return q }
+func (q *mockQuery) PageState(b []byte) core.CassandraQuery              { return q }
+func (q *mockQuery) WithContext(ctx context.Context) core.CassandraQuery { return q }
+func (q *mockQuery) Iter() core.CassandraIter                            { return &mockIter{} }
 
 type mockIter struct {
 	called bool
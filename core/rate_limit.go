@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles page fetches with token buckets for pages and rows per
+// second. It is safe for concurrent use, so one limiter can cap the combined
+// load of every paginator in a process.
+//
+// Every query attempt, retries and count queries included, takes a page token
+// and the rows it is expected to read before it runs, so concurrent callers
+// queue behind each other instead of waking together. Once the rows are known
+// the difference is charged or refunded.
+type RateLimiter struct {
+	mu    sync.Mutex
+	pages *tokenBucket
+	rows  *tokenBucket
+}
+
+// RateLimitObserver is an optional extension of MetricsCollector. Collectors
+// implementing it receive the time every fetch waited for the rate limiter.
+type RateLimitObserver interface {
+	ObserveRateLimitWait(wait time.Duration)
+}
+
+// NewRateLimiter creates a limiter allowing pagesPerSecond page fetches and
+// rowsPerSecond rows; zero or negative disables that limit. Pages are paced
+// evenly, while up to one second worth of rows may be read in a burst.
+func NewRateLimiter(pagesPerSecond, rowsPerSecond float64) *RateLimiter {
+	l := &RateLimiter{}
+	now := time.Now()
+	if pagesPerSecond > 0 {
+		l.pages = &tokenBucket{rate: pagesPerSecond, burst: 1, tokens: 1, last: now}
+	}
+	if rowsPerSecond > 0 {
+		l.rows = &tokenBucket{rate: rowsPerSecond, burst: rowsPerSecond, tokens: rowsPerSecond, last: now}
+	}
+	return l
+}
+
+// wait blocks until a query expected to read rows may run, reserving a page
+// token and the rows. It fails right away when the wait would outlast the
+// context deadline, and returns the time spent waiting.
+func (l *RateLimiter) wait(ctx context.Context, rows int) (time.Duration, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	var delay time.Duration
+	if l.pages != nil {
+		delay = l.pages.reserve(now, 1)
+	}
+	if l.rows != nil {
+		delay = max(delay, l.rows.reserve(now, float64(rows)))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		l.release(rows)
+		return 0, fmt.Errorf("rate limit wait of %v exceeds the deadline: %w", delay, context.DeadlineExceeded)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.release(rows)
+		return time.Since(now), ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
+
+// settle charges the rows a query read beyond those reserved by wait, or
+// refunds the ones it did not read.
+func (l *RateLimiter) settle(reserved, rows int) {
+	if l.rows == nil || rows == reserved {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rows.reserve(time.Now(), float64(rows-reserved))
+}
+
+// release returns the tokens of a query that did not run.
+func (l *RateLimiter) release(rows int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pages != nil {
+		l.pages.tokens = min(l.pages.tokens+1, l.pages.burst)
+	}
+	if l.rows != nil {
+		l.rows.tokens = min(l.rows.tokens+float64(rows), l.rows.burst)
+	}
+}
+
+// tokenBucket refills at rate tokens per second up to burst. Its balance may go
+// negative, in which case callers wait until it is repaid.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes n tokens and returns how long until the balance is non-negative.
+func (b *tokenBucket) reserve(now time.Time, n float64) time.Duration {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// throttle waits for Options.RateLimit before a query attempt expected to read
+// rows and reports the wait. Call settle with the rows actually read.
+func (p *Paginator) throttle(ctx context.Context, rows int) error {
+	if p.Opts.RateLimit == nil {
+		return nil
+	}
+	waited, err := p.Opts.RateLimit.wait(ctx, rows)
+	if waited > 0 {
+		if o, ok := p.Opts.Metrics.(RateLimitObserver); ok {
+			o.ObserveRateLimitWait(waited)
+		}
+	}
+	return err
+}
+
+// settle reconciles the rows reserved by throttle with the rows read.
+func (p *Paginator) settle(reserved, rows int) {
+	if p.Opts.RateLimit != nil {
+		p.Opts.RateLimit.settle(reserved, rows)
+	}
+}
@@ -0,0 +1,215 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// waitRecorder sums the waits reported to the optional RateLimitObserver.
+type waitRecorder struct {
+	mu    sync.Mutex
+	total time.Duration
+	waits int
+}
+
+func (r *waitRecorder) ObservePageFetch(int, time.Duration) {}
+func (r *waitRecorder) ObserveError(error)                  {}
+
+func (r *waitRecorder) ObserveRateLimitWait(wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += wait
+	r.waits++
+}
+
+func TestRateLimit_PagesPerSecond(t *testing.T) {
+	recorder := &waitRecorder{}
+	p := core.NewPaginator(newTableSession("users", numberedRows(30)), "SELECT * FROM users", core.Options{
+		PageSize:  5,
+		Metrics:   recorder,
+		RateLimit: core.NewRateLimiter(100, 0),
+	})
+
+	start := time.Now()
+	it := p.Pages("")
+	pages := 0
+	for it.Next() {
+		pages++
+	}
+	it.Close()
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 6 pages at 100/s: the first is free, the other five wait ~10ms each
+	if elapsed := time.Since(start); pages != 6 || elapsed < 40*time.Millisecond {
+		t.Errorf("expected 6 paced pages, got %d in %v", pages, elapsed)
+	}
+	if recorder.waits < 4 || recorder.total < 40*time.Millisecond {
+		t.Errorf("expected the waits to be reported, got %d totalling %v", recorder.waits, recorder.total)
+	}
+}
+
+func TestRateLimit_RowsPerSecond(t *testing.T) {
+	p := core.NewPaginator(newTableSession("users", numberedRows(300)), "SELECT * FROM users", core.Options{
+		PageSize:  50,
+		RateLimit: core.NewRateLimiter(0, 1000),
+	})
+
+	start := time.Now()
+	ids, _ := walkIDs(t, p)
+	assertSequence(t, ids, 300)
+
+	// 1000 rows of burst cover everything: no wait
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected no throttling within the burst, took %v", elapsed)
+	}
+
+	// At 200 rows/s, three pages of 100 leave the bucket 100 rows in debt,
+	// so the next fetch waits ~0.5s
+	p = core.NewPaginator(newTableSession("users", numberedRows(300)), "SELECT * FROM users", core.Options{
+		PageSize:  100,
+		RateLimit: core.NewRateLimiter(0, 200),
+	})
+	token := ""
+	for i := 0; i < 3; i++ {
+		if _, token, _ = p.NextWithToken(token); token == "" {
+			t.Fatalf("expected page %d to have a next token", i+1)
+		}
+	}
+
+	start = time.Now()
+	if _, _, err := p.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("expected the row debt to be repaid first, took %v", elapsed)
+	}
+}
+
+func TestRateLimit_SharedAcrossPaginators(t *testing.T) {
+	limiter := core.NewRateLimiter(100, 0)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := core.NewPaginator(newTableSession("users", numberedRows(20)), "SELECT * FROM users", core.Options{
+				PageSize:  5,
+				RateLimit: limiter,
+			})
+			it := p.Pages("")
+			defer it.Close()
+			for it.Next() {
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 3 paginators x 4 pages share 100 pages/s
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected the shared limiter to pace all 12 pages, took %v", elapsed)
+	}
+}
+
+func TestRateLimit_RespectsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	p := core.NewPaginator(newTableSession("users", numberedRows(20)), "SELECT * FROM users", core.Options{
+		PageSize:  5,
+		Context:   ctx,
+		RateLimit: core.NewRateLimiter(0.5, 0),
+	})
+
+	_, token, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	_, _, err = p.NextWithToken(token)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("expected an immediate failure, waited %v", elapsed)
+	}
+}
+
+func TestRateLimit_ThrottlesRetries(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", numberedRows(20))
+	s.InjectError("FROM users", caspagetest.ErrOverloaded, 2)
+	recorder := &waitRecorder{}
+	p := core.NewPaginator(s, "SELECT * FROM users", core.Options{
+		PageSize:  5,
+		Metrics:   recorder,
+		Retry:     &core.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		RateLimit: core.NewRateLimiter(20, 0),
+	})
+
+	// Three attempts at 20 pages/s: the two retries wait ~50ms each
+	start := time.Now()
+	if _, _, err := p.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected the retries to be throttled, took %v", elapsed)
+	}
+	if recorder.waits != 2 {
+		t.Errorf("expected 2 throttled attempts, got %d", recorder.waits)
+	}
+}
+
+func TestRateLimit_ThrottlesCounts(t *testing.T) {
+	p := core.NewPaginator(usersSession(40), "SELECT * FROM users", core.Options{
+		Keys:      &core.TableKeys{PartitionKeys: []string{"user_id"}},
+		RateLimit: core.NewRateLimiter(50, 0),
+	})
+
+	// 4 range queries at 50/s, run in parallel, still wait ~20ms each
+	start := time.Now()
+	res, err := p.Count(core.CountOptions{Mode: core.CountExact, Splits: 4, Parallelism: 4})
+	if err != nil || res.Count != 40 {
+		t.Fatalf("unexpected count %+v (%v)", res, err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the count queries to be throttled, took %v", elapsed)
+	}
+}
+
+func TestRateLimit_ConcurrentCallersReserveRows(t *testing.T) {
+	limiter := core.NewRateLimiter(0, 400)
+
+	// Two concurrent pages of 300 rows exceed the 400-row burst: the second
+	// must wait for the first's rows, ~0.5s, rather than start alongside it
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := core.NewPaginator(newTableSession("users", numberedRows(300)), "SELECT * FROM users", core.Options{
+				PageSize:  300,
+				RateLimit: limiter,
+			})
+			if _, _, err := p.Next(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected the second caller to wait for its rows, took %v", elapsed)
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// RetryPolicy retries page fetches that fail with a transient error. A retry
+// re-runs the query from the same page state, so it never skips or repeats rows.
+type RetryPolicy struct {
+	MaxAttempts int           // attempts including the first one (default: 3)
+	BaseDelay   time.Duration // backoff before the first retry, doubled each time (default: 100ms)
+	MaxDelay    time.Duration // upper bound of the backoff (default: 5s)
+
+	// Retryable classifies errors (default: IsRetryable).
+	Retryable func(error) bool
+}
+
+// RetryObserver is an optional extension of MetricsCollector. Collectors
+// implementing it are told about every retried attempt.
+type RetryObserver interface {
+	ObserveRetry(attempt int, err error)
+}
+
+// Cassandra error codes worth retrying: the coordinator could not reach enough
+// replicas in time or is temporarily unable to serve the request.
+var retryableCodes = map[int]bool{
+	gocql.ErrCodeUnavailable:   true,
+	gocql.ErrCodeOverloaded:    true,
+	gocql.ErrCodeBootstrapping: true,
+	gocql.ErrCodeReadTimeout:   true,
+}
+
+// IsRetryable reports whether err is a transient failure: a read timeout,
+// unavailable replicas, an overloaded or bootstrapping coordinator, or a
+// connection-level timeout, including Options.PageTimeout expiring. Context
+// cancellation and expired caller deadlines are never retryable.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrPageTimeout) {
+		return true
+	}
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return retryableCodes[coded.Code()]
+	}
+
+	if errors.Is(err, gocql.ErrTimeoutNoResponse) || errors.Is(err, gocql.ErrConnectionClosed) || errors.Is(err, gocql.ErrNoConnections) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// attempts returns the configured number of attempts.
+func (r *RetryPolicy) attempts() int {
+	if r.MaxAttempts <= 0 {
+		return 3
+	}
+	return r.MaxAttempts
+}
+
+// retryable applies the configured or default classification.
+func (r *RetryPolicy) retryable(err error) bool {
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns the delay after the given failed attempt: exponential, capped,
+// with the upper half randomised so concurrent scans do not retry in lockstep.
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	base, limit := r.BaseDelay, r.MaxDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if limit <= 0 {
+		limit = 5 * time.Second
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	delay = min(delay, limit)
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// retry runs attempt until it succeeds, fails with an error the policy does not
+// retry, runs out of attempts or ctx is done. It returns the number of attempts made.
+func (p *Paginator) retry(ctx context.Context, queryStr string, attempt func() error) (int, error) {
+	policy := p.Opts.Retry
+
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || policy == nil || n >= policy.attempts() || !policy.retryable(err) || ctx.Err() != nil {
+			return n, err
+		}
+
+		delay := policy.backoff(n)
+		p.Opts.log("query_retry", map[string]interface{}{
+			"query":    queryStr,
+			"attempt":  n,
+			"error":    err.Error(),
+			"delay_ms": delay.Milliseconds(),
+		})
+		if o, ok := p.Opts.Metrics.(RetryObserver); ok {
+			o.ObserveRetry(n, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return n, err
+		case <-timer.C:
+		}
+	}
+}
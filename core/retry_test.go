@@ -0,0 +1,177 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/gocql/gocql"
+)
+
+// codedError mimics a Cassandra error frame.
+type codedError struct{ code int }
+
+func (e codedError) Error() string { return fmt.Sprintf("cassandra error 0x%04x", e.code) }
+func (e codedError) Code() int     { return e.code }
+
+// flakySession holds n users and fails the first failures queries with err.
+func flakySession(n, failures int, err error) *caspagetest.Session {
+	s := newTableSession("users", numberedRows(n))
+	s.InjectError("", err, failures)
+	return s
+}
+
+// retryRecorder records retries reported to the optional RetryObserver.
+type retryRecorder struct {
+	mu       sync.Mutex
+	attempts []int
+}
+
+func (r *retryRecorder) ObservePageFetch(int, time.Duration) {}
+func (r *retryRecorder) ObserveError(error)                  {}
+
+func (r *retryRecorder) ObserveRetry(attempt int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+}
+
+func TestRetry_RecoversFromTransientErrors(t *testing.T) {
+	session := flakySession(8, 2, codedError{gocql.ErrCodeReadTimeout})
+	recorder := &retryRecorder{}
+	var events []string
+
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		PageSize: 5,
+		Metrics:  recorder,
+		Logger:   func(event string, _ map[string]interface{}) { events = append(events, event) },
+		Retry:    &core.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+
+	rows, _, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 5 || rows[0]["id"] != 0 {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+	if len(session.Queries()) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(session.Queries()))
+	}
+	if len(recorder.attempts) != 2 || recorder.attempts[1] != 2 {
+		t.Errorf("expected retries after attempts 1 and 2, got %v", recorder.attempts)
+	}
+	if len(events) != 3 || events[0] != "query_retry" || events[2] != "page_fetched" {
+		t.Errorf("unexpected log events: %v", events)
+	}
+}
+
+func TestRetry_ResumesSamePageState(t *testing.T) {
+	session := newTableSession("users", numberedRows(12))
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		PageSize: 5,
+		Retry:    &core.RetryPolicy{BaseDelay: time.Millisecond},
+	})
+
+	_, token, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The second page fails once and is re-read from the token's state
+	session.InjectError("", codedError{gocql.ErrCodeUnavailable}, 1)
+	rows, _, err := p.NextWithToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rowIDs(rows); len(got) != 5 || got[0] != 5 {
+		t.Errorf("expected ids 5..9, got %v", got)
+	}
+}
+
+func TestRetry_DoesNotRetryPermanentErrors(t *testing.T) {
+	session := flakySession(8, 1, codedError{gocql.ErrCodeSyntax})
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		Retry: &core.RetryPolicy{BaseDelay: time.Millisecond},
+	})
+
+	if _, _, err := p.Next(); !errors.Is(err, core.ErrQueryFailed) {
+		t.Fatalf("expected ErrQueryFailed, got %v", err)
+	}
+	if len(session.Queries()) != 1 {
+		t.Errorf("expected a single attempt, got %d", len(session.Queries()))
+	}
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	session := flakySession(8, 10, gocql.ErrTimeoutNoResponse)
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		Retry: &core.RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+	})
+
+	if _, _, err := p.Next(); !errors.Is(err, core.ErrQueryFailed) {
+		t.Fatalf("expected ErrQueryFailed, got %v", err)
+	}
+	if len(session.Queries()) != 4 {
+		t.Errorf("expected 4 attempts, got %d", len(session.Queries()))
+	}
+}
+
+func TestRetry_StopsWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	session := flakySession(8, 10, codedError{gocql.ErrCodeOverloaded})
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		Context: ctx,
+		Retry:   &core.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour},
+	})
+
+	start := time.Now()
+	if _, _, err := p.Next(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("backoff ignored the context: took %v", elapsed)
+	}
+	if len(session.Queries()) != 1 {
+		t.Errorf("expected 1 attempt, got %d", len(session.Queries()))
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+		{codedError{gocql.ErrCodeReadTimeout}, true},
+		{codedError{gocql.ErrCodeUnavailable}, true},
+		{codedError{gocql.ErrCodeOverloaded}, true},
+		{codedError{gocql.ErrCodeSyntax}, false},
+		{codedError{gocql.ErrCodeInvalid}, false},
+		{fmt.Errorf("wrapped: %w", gocql.ErrTimeoutNoResponse), true},
+		{gocql.ErrNoConnections, true},
+		{timeoutError{}, true},
+	}
+
+	for _, c := range cases {
+		if got := core.IsRetryable(c.err); got != c.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TableSchema describes a Cassandra table as read from system_schema.
+type TableSchema struct {
+	Keyspace string
+	Table    string
+	TableKeys
+
+	// Columns maps every column name to its CQL type (e.g. "text", "timeuuid").
+	Columns map[string]string
+}
+
+// KeysetColumns returns the columns that uniquely order rows inside a partition,
+// i.e. the clustering columns in primary key order.
+func (k *TableKeys) KeysetColumns() []string {
+	return append([]string(nil), k.ClusteringKeys...)
+}
+
+// HasColumn reports whether the table defines the given column.
+func (s *TableSchema) HasColumn(column string) bool {
+	_, ok := s.Columns[column]
+	return ok
+}
+
+// SchemaLoader loads table metadata for the paginator.
+// keyspace may be empty when the query does not qualify the table name.
+type SchemaLoader interface {
+	LoadSchema(keyspace, table string) (*TableSchema, error)
+}
+
+// SessionSchemaLoader reads system_schema.tables, columns and indexes through a CassandraSession.
+// Loaded schemas are cached, so one loader can be shared by many paginators.
+type SessionSchemaLoader struct {
+	Session  CassandraSession
+	Keyspace string          // used when the query does not name a keyspace
+	Context  context.Context // optional context for the metadata queries
+
+	mu    sync.Mutex
+	cache map[string]*TableSchema
+}
+
+// NewSessionSchemaLoader creates a loader that falls back to the given keyspace.
+func NewSessionSchemaLoader(session CassandraSession, keyspace string) *SessionSchemaLoader {
+	return &SessionSchemaLoader{Session: session, Keyspace: keyspace}
+}
+
+// LoadSchema implements SchemaLoader.
+func (l *SessionSchemaLoader) LoadSchema(keyspace, table string) (*TableSchema, error) {
+	if keyspace == "" {
+		keyspace = l.Keyspace
+	}
+	if keyspace == "" {
+		return nil, fmt.Errorf("%w: no keyspace for table %q", ErrTableNotFound, table)
+	}
+
+	cacheKey := keyspace + "." + table
+	l.mu.Lock()
+	cached, ok := l.cache[cacheKey]
+	l.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	schema, err := l.load(keyspace, table)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	if l.cache == nil {
+		l.cache = map[string]*TableSchema{}
+	}
+	l.cache[cacheKey] = schema
+	l.mu.Unlock()
+
+	return schema, nil
+}
+
+// load runs the system_schema queries for a single table.
+func (l *SessionSchemaLoader) load(keyspace, table string) (*TableSchema, error) {
+	// 1️⃣ Make sure the table exists
+	tables, err := l.scan("SELECT table_name FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?", keyspace, table)
+	if err != nil {
+		return nil, fmt.Errorf("load schema for %s.%s: %w", keyspace, table, err)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%w: %s.%s", ErrTableNotFound, keyspace, table)
+	}
+
+	// 2️⃣ Columns, split by kind and ordered by position
+	columns, err := l.scan("SELECT column_name, kind, position, clustering_order, type FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?", keyspace, table)
+	if err != nil {
+		return nil, fmt.Errorf("load schema for %s.%s: %w", keyspace, table, err)
+	}
+
+	schema := &TableSchema{
+		Keyspace: keyspace,
+		Table:    table,
+		Columns:  make(map[string]string, len(columns)),
+	}
+	schema.ClusteringOrder = map[string]string{}
+
+	var partition, clustering []schemaColumn
+	for _, row := range columns {
+		col := schemaColumn{
+			name:     toString(row["column_name"]),
+			position: toInt(row["position"]),
+		}
+		schema.Columns[col.name] = toString(row["type"])
+
+		switch toString(row["kind"]) {
+		case "partition_key":
+			partition = append(partition, col)
+		case "clustering":
+			clustering = append(clustering, col)
+			schema.ClusteringOrder[col.name] = strings.ToUpper(toString(row["clustering_order"]))
+		}
+	}
+	schema.PartitionKeys = sortedColumnNames(partition)
+	schema.ClusteringKeys = sortedColumnNames(clustering)
+
+	// 3️⃣ Secondary indexes; the target is stored in the options map
+	indexes, err := l.scan("SELECT index_name, options FROM system_schema.indexes WHERE keyspace_name = ? AND table_name = ?", keyspace, table)
+	if err != nil {
+		return nil, fmt.Errorf("load schema for %s.%s: %w", keyspace, table, err)
+	}
+	for _, row := range indexes {
+		options, _ := row["options"].(map[string]string)
+		if target := indexTarget(options["target"]); target != "" {
+			schema.IndexedColumns = append(schema.IndexedColumns, target)
+		}
+	}
+
+	return schema, nil
+}
+
+// scan runs a metadata query and returns all rows.
+func (l *SessionSchemaLoader) scan(stmt string, values ...interface{}) ([]map[string]interface{}, error) {
+	q := l.Session.Query(stmt, values...)
+	if l.Context != nil {
+		q = q.WithContext(l.Context)
+	}
+
+	iter := q.Iter()
+	rows := []map[string]interface{}{}
+	row := map[string]interface{}{}
+	for iter.MapScan(row) {
+		rows = append(rows, row)
+		row = map[string]interface{}{}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// StaticSchema is a SchemaLoader serving fixed schemas, handy for tests.
+// Keys are "keyspace.table" or just "table".
+type StaticSchema map[string]*TableSchema
+
+// LoadSchema implements SchemaLoader.
+func (s StaticSchema) LoadSchema(keyspace, table string) (*TableSchema, error) {
+	if keyspace != "" {
+		if schema, ok := s[keyspace+"."+table]; ok {
+			return schema, nil
+		}
+	}
+	if schema, ok := s[table]; ok {
+		return schema, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTableNotFound, table)
+}
+
+// Schema returns the table schema, loading it on first use through Opts.Schema.
+// It returns (nil, nil) when no loader is configured. Only a loaded schema is
+// kept; after an error the next call tries again.
+func (p *Paginator) Schema() (*TableSchema, error) {
+	if p.Opts.Schema == nil {
+		return nil, nil
+	}
+	if p.parent != nil {
+		return p.parent.Schema()
+	}
+
+	p.schemaMu.Lock()
+	defer p.schemaMu.Unlock()
+	if p.schema != nil {
+		return p.schema, nil
+	}
+
+	keyspace, table, ok := parseTableName(p.Query)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot find table name in query", ErrTableNotFound)
+	}
+	schema, err := p.Opts.Schema.LoadSchema(keyspace, table)
+	if err != nil {
+		return nil, err
+	}
+	p.schema = schema
+	return schema, nil
+}
+
+// keys returns the key metadata from Opts.Keys, falling back to the loaded schema.
+func (p *Paginator) keys() *TableKeys {
+	if p.Opts.Keys != nil {
+		return p.Opts.Keys
+	}
+	if schema, err := p.Schema(); err == nil && schema != nil {
+		return &schema.TableKeys
+	}
+	return nil
+}
+
+// validateAgainstSchema checks that every filter and selected column exists in the table.
+func (p *Paginator) validateAgainstSchema() error {
+	schema, err := p.Schema()
+	if err != nil || schema == nil {
+		return err
+	}
+
+	for k := range p.Opts.Filters {
+		column, _ := parseFilterKey(k)
+		if !schema.HasColumn(unquote(column)) {
+			return fmt.Errorf("%w: filter on %q", ErrUnknownColumn, column)
+		}
+	}
+
+	for _, column := range p.Opts.Columns {
+		// Expressions such as writetime(x), COUNT(*) or aliases are passed through untouched
+		if strings.ContainsAny(column, "( ") {
+			continue
+		}
+		if !schema.HasColumn(unquote(column)) {
+			return fmt.Errorf("%w: selected column %q", ErrUnknownColumn, column)
+		}
+	}
+
+	return nil
+}
+
+var tableNamePattern = regexp.MustCompile(`(?i)\bFROM\s+("?[\w]+"?)(?:\s*\.\s*("?[\w]+"?))?`)
+
+// parseTableName extracts the (optional) keyspace and table from a SELECT statement.
+func parseTableName(query string) (string, string, bool) {
+	m := tableNamePattern.FindStringSubmatch(query)
+	if m == nil {
+		return "", "", false
+	}
+	if m[2] == "" {
+		return "", unquote(m[1]), true
+	}
+	return unquote(m[1]), unquote(m[2]), true
+}
+
+// indexTarget extracts the column name from an index target such as "values(tags)".
+func indexTarget(target string) string {
+	if i := strings.Index(target, "("); i >= 0 && strings.HasSuffix(target, ")") {
+		target = target[i+1 : len(target)-1]
+	}
+	return unquote(target)
+}
+
+// unquote strips CQL double quotes from an identifier; unquoted identifiers are case-insensitive.
+func unquote(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		return name[1 : len(name)-1]
+	}
+	return strings.ToLower(name)
+}
+
+type schemaColumn struct {
+	name     string
+	position int
+}
+
+func sortedColumnNames(cols []schemaColumn) []string {
+	sort.Slice(cols, func(i, j int) bool { return cols[i].position < cols[j].position })
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	return names
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func toInt(v interface{}) int {
+	return int(toInt64(v))
+}
@@ -0,0 +1,197 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// ---- Mock system_schema session ----
+
+type schemaSession struct {
+	rows    map[string][]map[string]interface{} // keyed by system_schema table
+	queries int
+}
+
+func (s *schemaSession) Query(q string, args ...interface{}) core.CassandraQuery {
+	s.queries++
+	for table, rows := range s.rows {
+		if strings.Contains(q, "system_schema."+table+" ") {
+			return &schemaQuery{rows: rows}
+		}
+	}
+	return &schemaQuery{}
+}
+
+type schemaQuery struct {
+	rows []map[string]interface{}
+}
+
+func (q *schemaQuery) PageSize(n int) core.CassandraQuery                  { return q }
+func (q *schemaQuery) PageState(b []byte) core.CassandraQuery              { return q }
+func (q *schemaQuery) WithContext(ctx context.Context) core.CassandraQuery { return q }
+func (q *schemaQuery) Iter() core.CassandraIter                            { return &schemaIter{rows: q.rows} }
+
+type schemaIter struct {
+	rows []map[string]interface{}
+	pos  int
+}
+
+func (i *schemaIter) MapScan(m map[string]interface{}) bool {
+	if i.pos >= len(i.rows) {
+		return false
+	}
+	for k, v := range i.rows[i.pos] {
+		m[k] = v
+	}
+	i.pos++
+	return true
+}
+
+func (i *schemaIter) PageState() []byte { return nil }
+func (i *schemaIter) Close() error      { return nil }
+
+func eventsSchemaSession() *schemaSession {
+	return &schemaSession{rows: map[string][]map[string]interface{}{
+		"tables": {{"table_name": "events"}},
+		"columns": {
+			{"column_name": "day", "kind": "partition_key", "position": 1, "clustering_order": "none", "type": "date"},
+			{"column_name": "device_id", "kind": "partition_key", "position": 0, "clustering_order": "none", "type": "text"},
+			{"column_name": "ts", "kind": "clustering", "position": 0, "clustering_order": "desc", "type": "timestamp"},
+			{"column_name": "payload", "kind": "regular", "position": -1, "clustering_order": "none", "type": "blob"},
+			{"column_name": "status", "kind": "regular", "position": -1, "clustering_order": "none", "type": "text"},
+		},
+		"indexes": {{"index_name": "events_status_idx", "options": map[string]string{"target": "status"}}},
+	}}
+}
+
+// ---- Tests ----
+
+func TestSessionSchemaLoader(t *testing.T) {
+	session := eventsSchemaSession()
+	loader := core.NewSessionSchemaLoader(session, "metrics")
+
+	schema, err := loader.LoadSchema("", "events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(schema.PartitionKeys, []string{"device_id", "day"}) {
+		t.Errorf("unexpected partition keys: %v", schema.PartitionKeys)
+	}
+	if !reflect.DeepEqual(schema.KeysetColumns(), []string{"ts"}) {
+		t.Errorf("unexpected keyset columns: %v", schema.KeysetColumns())
+	}
+	if schema.ClusteringOrder["ts"] != "DESC" {
+		t.Errorf("expected ts DESC, got %q", schema.ClusteringOrder["ts"])
+	}
+	if !reflect.DeepEqual(schema.IndexedColumns, []string{"status"}) {
+		t.Errorf("unexpected indexes: %v", schema.IndexedColumns)
+	}
+	if schema.Columns["payload"] != "blob" {
+		t.Errorf("unexpected payload type: %q", schema.Columns["payload"])
+	}
+
+	// Second load is served from the cache
+	queries := session.queries
+	if _, err := loader.LoadSchema("metrics", "events"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.queries != queries {
+		t.Errorf("expected cached schema, got %d extra queries", session.queries-queries)
+	}
+}
+
+func TestSessionSchemaLoader_TableNotFound(t *testing.T) {
+	loader := core.NewSessionSchemaLoader(&schemaSession{}, "metrics")
+	if _, err := loader.LoadSchema("", "missing"); !errors.Is(err, core.ErrTableNotFound) {
+		t.Fatalf("expected ErrTableNotFound, got %v", err)
+	}
+}
+
+func TestPaginator_SchemaValidation(t *testing.T) {
+	schema := core.StaticSchema{
+		"metrics.events": {
+			Keyspace:  "metrics",
+			Table:     "events",
+			TableKeys: core.TableKeys{PartitionKeys: []string{"device_id"}, ClusteringKeys: []string{"ts"}},
+			Columns:   map[string]string{"device_id": "text", "ts": "timestamp", "payload": "blob"},
+		},
+	}
+
+	p := core.NewPaginator(&mockSession{}, "SELECT * FROM metrics.events", core.Options{
+		Schema:  schema,
+		Filters: map[string]interface{}{"colour": "red"},
+	})
+	if _, _, err := p.Next(); !errors.Is(err, core.ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn for filter, got %v", err)
+	}
+
+	p = core.NewPaginator(&mockSession{}, "SELECT * FROM metrics.events", core.Options{
+		Schema:  schema,
+		Columns: []string{"device_id", "size"},
+	})
+	if _, _, err := p.Next(); !errors.Is(err, core.ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn for column, got %v", err)
+	}
+
+	// Schema keys feed the ALLOW FILTERING guard
+	p = core.NewPaginator(&mockSession{}, "SELECT * FROM metrics.events", core.Options{
+		Schema:    schema,
+		Filters:   map[string]interface{}{"payload": []byte("x")},
+		Filtering: core.FilteringGuard,
+	})
+	if _, _, err := p.Next(); !errors.Is(err, core.ErrFilteringRequired) {
+		t.Fatalf("expected ErrFilteringRequired, got %v", err)
+	}
+
+	p = core.NewPaginator(&mockSession{}, "SELECT * FROM metrics.events", core.Options{
+		Schema:  schema,
+		Filters: map[string]interface{}{"device_id": "d1", "ts >": 10},
+	})
+	if _, _, err := p.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// flakyLoader fails its first loads, then serves schema.
+type flakyLoader struct {
+	schema   core.StaticSchema
+	failures int
+	loads    int
+}
+
+func (l *flakyLoader) LoadSchema(keyspace, table string) (*core.TableSchema, error) {
+	l.loads++
+	if l.loads <= l.failures {
+		return nil, errors.New("connection refused")
+	}
+	return l.schema.LoadSchema(keyspace, table)
+}
+
+func TestPaginator_SchemaRetriedAfterError(t *testing.T) {
+	loader := &flakyLoader{failures: 1, schema: core.StaticSchema{"metrics.events": {
+		Keyspace:  "metrics",
+		Table:     "events",
+		TableKeys: core.TableKeys{PartitionKeys: []string{"device_id"}},
+		Columns:   map[string]string{"device_id": "text"},
+	}}}
+	p := core.NewPaginator(&mockSession{}, "SELECT * FROM metrics.events", core.Options{Schema: loader})
+
+	if _, err := p.Schema(); err == nil {
+		t.Fatal("expected the first load to fail")
+	}
+	schema, err := p.Schema()
+	if err != nil || schema == nil || schema.Table != "events" {
+		t.Fatalf("expected the schema to load on retry, got %+v (%v)", schema, err)
+	}
+
+	// Once loaded it is kept
+	if _, err := p.Schema(); err != nil || loader.loads != 2 {
+		t.Errorf("expected 2 loads, got %d (%v)", loader.loads, err)
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// ShardLayout describes how a ScyllaDB node divides the token ring among its
+// CPU shards, as advertised in the SCYLLA_NR_SHARDS and
+// SCYLLA_SHARDING_IGNORE_MSB options of the native protocol handshake.
+//
+// Scylla ignores the IgnoreMSB most significant bits of a token before mapping
+// it to a shard, so every shard owns 2^IgnoreMSB interleaved slices of the ring
+// rather than one contiguous range.
+type ShardLayout struct {
+	Shards    int // shards per node
+	IgnoreMSB int // sharding_ignore_msb_bits of the node (12 by default)
+}
+
+// Valid reports whether the layout can map tokens to shards.
+func (l ShardLayout) Valid() bool {
+	return l.Shards > 0 && l.IgnoreMSB >= 0 && l.IgnoreMSB < 64
+}
+
+// ShardOf returns the shard owning the token, using Scylla's Murmur3 sharding.
+func (l ShardLayout) ShardOf(token int64) int {
+	if !l.Valid() || l.Shards == 1 {
+		return 0
+	}
+	biased := uint64(token) + 1<<63
+	hi, _ := bits.Mul64(biased<<l.IgnoreMSB, uint64(l.Shards))
+	return int(hi)
+}
+
+// ShardRanges divides r into sub-ranges each owned by a single shard, grouped
+// by shard: ShardRanges(r)[i] lists the ranges of shard i in ring order.
+// Scanning each group on its own worker keeps every worker on one shard.
+func (l ShardLayout) ShardRanges(r TokenRange) [][]TokenRange {
+	if !l.Valid() || l.Shards == 1 || r.End <= r.Start {
+		return [][]TokenRange{{r}}
+	}
+
+	groups := make([][]TokenRange, l.Shards)
+	for _, sub := range l.Split(r) {
+		shard := l.ShardOf(sub.End)
+		groups[shard] = append(groups[shard], sub)
+	}
+	return groups
+}
+
+// Split divides r at every shard boundary, returning contiguous sub-ranges in
+// ring order that are each owned by a single shard.
+func (l ShardLayout) Split(r TokenRange) []TokenRange {
+	if !l.Valid() || l.Shards == 1 || r.End <= r.Start {
+		return []TokenRange{r}
+	}
+
+	var ranges []TokenRange
+	start := r.Start
+	for start < r.End {
+		end := l.shardEnd(start + 1)
+		if end > r.End || end < start {
+			end = r.End
+		}
+		ranges = append(ranges, TokenRange{Start: start, End: end})
+		start = end
+	}
+	return ranges
+}
+
+// shardEnd returns the last token owned by the shard of token before the ring
+// passes to the next shard.
+//
+// With P = 2^(64-IgnoreMSB), the biased token u = token + 2^63 belongs to
+// shard floor((u mod P) * Shards / P), so shard s starts at offset
+// ceil(s * P / Shards) of every period of P tokens.
+func (l ShardLayout) shardEnd(token int64) int64 {
+	period := new(big.Int).Lsh(big.NewInt(1), uint(64-l.IgnoreMSB))
+	biased := new(big.Int).Add(big.NewInt(token), new(big.Int).Lsh(big.NewInt(1), 63))
+
+	base, offset := new(big.Int).QuoRem(biased, period, new(big.Int))
+	base.Mul(base, period)
+
+	// Offset within the period where the next shard starts
+	next := int64(l.ShardOf(token)) + 1
+	bound := new(big.Int).Mul(big.NewInt(next), period)
+	bound.Add(bound, big.NewInt(int64(l.Shards)-1))
+	bound.Quo(bound, big.NewInt(int64(l.Shards)))
+	if bound.Cmp(offset) <= 0 {
+		bound.Set(period)
+	}
+
+	// Back to a token: base + bound - 1 - 2^63
+	end := bound.Add(bound, base)
+	end.Sub(end, big.NewInt(1))
+	end.Sub(end, new(big.Int).Lsh(big.NewInt(1), 63))
+	if !end.IsInt64() {
+		return math.MaxInt64
+	}
+	return end.Int64()
+}
+
+// String formats the layout as "8 shards, ignore_msb 12".
+func (l ShardLayout) String() string {
+	return fmt.Sprintf("%d shards, ignore_msb %d", l.Shards, l.IgnoreMSB)
+}
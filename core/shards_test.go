@@ -0,0 +1,213 @@
+package core_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// referenceShard is Scylla's dht::shard_of for Murmur3 tokens, in big integers.
+func referenceShard(l core.ShardLayout, token int64) int {
+	two64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	u := new(big.Int).Add(big.NewInt(token), new(big.Int).Lsh(big.NewInt(1), 63))
+	u.Lsh(u, uint(l.IgnoreMSB)).Mod(u, two64)
+	u.Mul(u, big.NewInt(int64(l.Shards))).Div(u, two64)
+	return int(u.Int64())
+}
+
+func TestShardLayout_ShardOf(t *testing.T) {
+	layouts := []core.ShardLayout{{Shards: 8, IgnoreMSB: 12}, {Shards: 3, IgnoreMSB: 0}, {Shards: 7, IgnoreMSB: 4}}
+	tokens := []int64{math.MinInt64 + 1, -1 << 62, -12345, 0, 1, 98765, 1 << 61, math.MaxInt64}
+	for _, l := range layouts {
+		for _, token := range tokens {
+			if got, want := l.ShardOf(token), referenceShard(l, token); got != want {
+				t.Errorf("%v: token %d: expected shard %d, got %d", l, token, want, got)
+			}
+		}
+	}
+
+	if got := (core.ShardLayout{Shards: 4}).ShardOf(0); got != 2 {
+		t.Errorf("expected token 0 on shard 2 of 4, got %d", got)
+	}
+}
+
+func TestShardLayout_Split(t *testing.T) {
+	l := core.ShardLayout{Shards: 3, IgnoreMSB: 4}
+	ranges := l.Split(core.FullRing())
+
+	// 2^4 periods of the ring, each divided among 3 shards
+	if len(ranges) != 48 {
+		t.Fatalf("expected 48 ranges, got %d", len(ranges))
+	}
+	if ranges[0].Start != math.MinInt64 || ranges[len(ranges)-1].End != math.MaxInt64 {
+		t.Errorf("ranges do not cover the ring: %v .. %v", ranges[0], ranges[len(ranges)-1])
+	}
+	for i, r := range ranges {
+		if i > 0 && r.Start != ranges[i-1].End {
+			t.Errorf("gap between %v and %v", ranges[i-1], r)
+		}
+		shard := l.ShardOf(r.End)
+		if referenceShard(l, r.Start+1) != shard || referenceShard(l, r.End) != shard {
+			t.Errorf("range %v spans shards", r)
+		}
+		if i > 0 && l.ShardOf(ranges[i-1].End) == shard {
+			t.Errorf("ranges %v and %v are on the same shard %d", ranges[i-1], r, shard)
+		}
+	}
+
+	// A range inside one shard's slice is returned unchanged
+	inner := core.TokenRange{Start: ranges[5].Start + 10, End: ranges[5].End - 10}
+	if got := l.Split(inner); len(got) != 1 || got[0] != inner {
+		t.Errorf("expected %v unchanged, got %v", inner, got)
+	}
+}
+
+func TestShardLayout_ShardRanges(t *testing.T) {
+	l := core.ShardLayout{Shards: 4, IgnoreMSB: 2}
+	groups := l.ShardRanges(core.TokenRange{Start: -1000, End: 1 << 62})
+
+	if len(groups) != 4 {
+		t.Fatalf("expected 4 groups, got %d", len(groups))
+	}
+	for shard, group := range groups {
+		for _, r := range group {
+			if l.ShardOf(r.Start+1) != shard || l.ShardOf(r.End) != shard {
+				t.Errorf("range %v is not on shard %d", r, shard)
+			}
+		}
+	}
+
+	if got := (core.ShardLayout{Shards: 1}).ShardRanges(core.FullRing()); len(got) != 1 || len(got[0]) != 1 {
+		t.Errorf("expected the whole range for a single shard, got %v", got)
+	}
+}
+
+func TestPaginator_CountByShard(t *testing.T) {
+	l := core.ShardLayout{Shards: 2, IgnoreMSB: 2}
+	session := usersSession(80)
+
+	// Without a token range the count would need a query per slice of the ring
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		Keys: &core.TableKeys{PartitionKeys: []string{"user_id"}},
+	})
+	if _, err := p.Count(core.CountOptions{Mode: core.CountExact, Shards: &l}); !errors.Is(err, core.ErrCountUnsupported) {
+		t.Fatalf("expected ErrCountUnsupported, got %v", err)
+	}
+	if n := len(session.Queries()); n != 0 {
+		t.Fatalf("expected no queries, got %d", n)
+	}
+
+	// Half the ring spans 2 of the 4 slice periods, one slice per shard in each
+	r := core.FullRing().Split(2)[0]
+	p.Opts.TokenRange = &r
+	res, err := p.Count(core.CountOptions{Mode: core.CountExact, Shards: &l})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inRange := 0
+	for _, row := range session.Table("users").Rows() {
+		if token := caspagetest.Token(row["user_id"]); token > r.Start && token <= r.End {
+			inRange++
+		}
+	}
+	if res.Count != int64(inRange) {
+		t.Errorf("expected the ranges to count the %d rows in %v once, got %d", inRange, r, res.Count)
+	}
+
+	queries := session.Queries()
+	if len(queries) != 4 {
+		t.Errorf("expected 4 queries, got %d", len(queries))
+	}
+	shards := map[int]int{}
+	for _, q := range queries {
+		if strings.Count(q.Statement, "token(user_id) >") != 1 {
+			t.Errorf("expected one token range, got %q", q.Statement)
+		}
+		start, end := q.Values[0].(int64), q.Values[1].(int64)
+		if l.ShardOf(start+1) != l.ShardOf(end) {
+			t.Errorf("range (%d, %d] spans shards", start, end)
+		}
+		shards[l.ShardOf(end)]++
+	}
+	if shards[0] != 2 || shards[1] != 2 {
+		t.Errorf("expected 2 ranges per shard, got %v", shards)
+	}
+}
+
+func TestPaginator_TokenRange(t *testing.T) {
+	session := usersSession(40)
+	rows := session.Table("users").Rows()
+	for i, row := range rows {
+		row["org"], row["active"] = "acme", i%2 == 0
+	}
+	session.AddTable("users", rows)
+
+	// The first quarter of the ring
+	r := core.FullRing().Split(4)[0]
+	var want []int
+	for _, row := range rows {
+		token := caspagetest.Token(row["org"], row["user_id"])
+		if row["active"] == true && token > r.Start && token <= r.End {
+			want = append(want, row["id"].(int))
+		}
+	}
+	if len(want) == 0 {
+		t.Fatal("expected some rows in the range")
+	}
+
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{
+		PageSize:   40,
+		Keys:       &core.TableKeys{PartitionKeys: []string{"org", "user_id"}},
+		Filters:    map[string]interface{}{"active": true},
+		TokenRange: &r,
+	})
+	got, _, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(got), want) {
+		t.Errorf("expected the active rows in %v, got %v want %v", r, ids(got), want)
+	}
+	if stmt := session.Queries()[0].Statement; !strings.Contains(stmt, "AND token(org, user_id) > ? AND token(org, user_id) <= ?") {
+		t.Errorf("expected a token range relation, got %q", stmt)
+	}
+
+	// Count splits the configured range rather than the whole ring
+	counts := usersSession(40)
+	p = core.NewPaginator(counts, "SELECT * FROM users", core.Options{
+		Keys:       &core.TableKeys{PartitionKeys: []string{"user_id"}},
+		TokenRange: &r,
+	})
+	res, err := p.Count(core.CountOptions{Mode: core.CountExact, Splits: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inRange := 0
+	for _, row := range counts.Table("users").Rows() {
+		if token := caspagetest.Token(row["user_id"]); token > r.Start && token <= r.End {
+			inRange++
+		}
+	}
+	if res.Count != int64(inRange) {
+		t.Errorf("expected %d rows in %v, got %d", inRange, r, res.Count)
+	}
+	for _, q := range counts.Queries() {
+		for _, b := range q.Values[len(q.Values)-2:] {
+			if b := b.(int64); b < r.Start || b > r.End {
+				t.Errorf("bound %d outside %v", b, r)
+			}
+		}
+	}
+
+	// Without partition keys the range cannot be expressed
+	p = core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 10, TokenRange: &r})
+	if _, _, err := p.Next(); !errors.Is(err, core.ErrTokenRangeUnavailable) {
+		t.Errorf("expected ErrTokenRangeUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// RowStream delivers the rows of a paginator on a channel:
+//
+//	s := core.Stream(ctx, p)
+//	for row := range s.Rows() {
+//		process(row)
+//	}
+//	if err := s.Err(); err != nil { ... }
+//
+// Rows are handed over one at a time, so a slow consumer holds back the
+// fetcher; at most max(Options.PrefetchDepth, 1) pages are buffered ahead.
+// Cancel ctx to stop a stream early.
+type RowStream[T any] struct {
+	rows chan T
+
+	mu    sync.Mutex
+	token string
+	err   error
+}
+
+// Stream streams the rows of p from the first page.
+func Stream(ctx context.Context, p *Paginator) *RowStream[map[string]interface{}] {
+	return StreamWithToken(ctx, p, "")
+}
+
+// StreamWithToken streams the rows of p starting at the given token.
+func StreamWithToken(ctx context.Context, p *Paginator, token string) *RowStream[map[string]interface{}] {
+	return stream(ctx, p, token, func(rows []map[string]interface{}) ([]map[string]interface{}, error) {
+		return rows, nil
+	})
+}
+
+// StreamAs streams typed rows (e.g., User) instead of map[string]interface{}.
+func StreamAs[T any](ctx context.Context, p *Paginator) *RowStream[T] {
+	return StreamWithTokenAs[T](ctx, p, "")
+}
+
+// StreamWithTokenAs streams typed rows starting at the given token.
+func StreamWithTokenAs[T any](ctx context.Context, p *Paginator, token string) *RowStream[T] {
+	return stream(ctx, p, token, func(rows []map[string]interface{}) ([]T, error) {
+		typed, _, err := MapTo[T](rows, "")
+		return typed, err
+	})
+}
+
+// Rows returns the channel of rows. It is closed when the results are
+// exhausted, an error occurs or ctx is cancelled.
+func (s *RowStream[T]) Rows() <-chan T {
+	return s.rows
+}
+
+// Err returns the error that ended the stream, or ctx.Err() after cancellation.
+// It is final once the Rows channel is closed.
+func (s *RowStream[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Token returns the token after the last page whose rows were all received.
+// Passing it to StreamWithToken or NextWithToken resumes without gaps; rows of
+// a partially received page are delivered again. It is "" once the stream completes.
+func (s *RowStream[T]) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+func stream[T any](ctx context.Context, p *Paginator, token string, convert func([]map[string]interface{}) ([]T, error)) *RowStream[T] {
+	s := &RowStream[T]{rows: make(chan T), token: token}
+
+	depth := p.Opts.PrefetchDepth
+	if depth < 1 {
+		depth = 1
+	}
+
+	go func() {
+		defer close(s.rows)
+
+		it := p.pages(ctx, token, depth)
+		defer it.Close()
+
+		for it.Next() {
+			rows, err := convert(it.Rows())
+			if err != nil {
+				s.finish(err)
+				return
+			}
+			for _, row := range rows {
+				select {
+				case s.rows <- row:
+				case <-ctx.Done():
+					s.finish(ctx.Err())
+					return
+				}
+			}
+			s.commit(it.Token())
+		}
+		s.finish(it.Err())
+	}()
+
+	return s
+}
+
+func (s *RowStream[T]) commit(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+func (s *RowStream[T]) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+func TestStream_DeliversAllRows(t *testing.T) {
+	session := newTableSession("users", numberedRows(23))
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5})
+
+	s := core.Stream(context.Background(), p)
+	var ids []int
+	for row := range s.Rows() {
+		ids = append(ids, row["id"].(int))
+	}
+
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 23 || ids[0] != 0 || ids[22] != 22 {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+	if s.Token() != "" {
+		t.Errorf("expected empty token after completion, got %q", s.Token())
+	}
+}
+
+func TestStreamAs_TypedRows(t *testing.T) {
+	type Row struct {
+		ID int `mapstructure:"id"`
+	}
+
+	session := newTableSession("users", numberedRows(7))
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 3, PrefetchDepth: 2})
+
+	s := core.StreamAs[Row](context.Background(), p)
+	sum := 0
+	for row := range s.Rows() {
+		sum += row.ID
+	}
+
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum != 21 {
+		t.Errorf("expected ids 0..6 (sum 21), got sum %d", sum)
+	}
+}
+
+func TestStream_CancelAndResume(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	session := newTableSession("users", numberedRows(40))
+	p := core.NewPaginator(session, "SELECT * FROM users", core.Options{PageSize: 5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := core.Stream(ctx, p)
+
+	// Receive two full pages and part of the third, then cancel
+	received := 0
+	for ; received < 12; received++ {
+		<-s.Rows()
+	}
+	cancel()
+	for range s.Rows() {
+		received++ // a row may still slip through before the stream sees ctx.Done
+	}
+
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", s.Err())
+	}
+
+	// The committed token resumes at the first row of the partially received page
+	want := received / 5 * 5
+	resumed := core.StreamWithToken(context.Background(), p, s.Token())
+	first, ok := <-resumed.Rows()
+	if !ok || first["id"] != want {
+		t.Fatalf("expected resume at id %d, got %v (ok=%v), err: %v", want, first, ok, resumed.Err())
+	}
+	for range resumed.Rows() {
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines leaked: %d before, %d after", before, after)
+	}
+}
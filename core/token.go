@@ -9,21 +9,31 @@ import (
 // TokenEnvelope wraps both current Cassandra page state and previous token
 type TokenEnvelope struct {
 	State []byte `json:"state,omitempty"`
+	Skip  int    `json:"skip,omitempty"` // rows of the State page already returned
 	Prev  string `json:"prev,omitempty"`
+
+	// Keyset cursors: clustering values of the first and last row of the page
+	First []TypedValue `json:"first,omitempty"`
+	Last  []TypedValue `json:"last,omitempty"`
 }
 
 // EncodeToken converts a TokenEnvelope into a base64-encoded JSON string
 func EncodeToken(state []byte, prev string) string {
-	if len(state) == 0 && prev == "" {
-		return ""
-	}
-
 	env := TokenEnvelope{
 		State: state,
 		Prev:  prev,
 	}
+	return env.Encode()
+}
 
-	b, err := json.Marshal(env)
+// Encode converts the envelope into a base64-encoded JSON string.
+// An empty envelope encodes to "".
+func (e *TokenEnvelope) Encode() string {
+	if len(e.State) == 0 && e.Skip == 0 && e.Prev == "" && len(e.First) == 0 && len(e.Last) == 0 {
+		return ""
+	}
+
+	b, err := json.Marshal(e)
 	if err != nil {
 		return ""
 	}
@@ -48,3 +58,57 @@ func DecodeToken(token string) (*TokenEnvelope, error) {
 
 	return &env, nil
 }
+
+// CompositeToken tracks the position of every source of a merged paginator
+// (partitions, buckets or tables) in a single token.
+type CompositeToken struct {
+	Parts []SubCursor `json:"parts,omitempty"`
+	Prev  string      `json:"prev,omitempty"`
+}
+
+// SubCursor is the position of one source: resume from State and drop the
+// first Skip rows, unless the source is Done.
+type SubCursor struct {
+	State []byte `json:"state,omitempty"`
+	Skip  int    `json:"skip,omitempty"`
+	Done  bool   `json:"done,omitempty"`
+}
+
+// EncodeCompositeToken converts a CompositeToken into a base64-encoded JSON string
+func EncodeCompositeToken(ct CompositeToken) string {
+	return encodeJSONToken(ct)
+}
+
+// DecodeCompositeToken decodes a base64 token into a CompositeToken
+func DecodeCompositeToken(token string) (*CompositeToken, error) {
+	var ct CompositeToken
+	if err := decodeJSONToken(token, &ct); err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
+
+// encodeJSONToken marshals v to base64-encoded JSON, returning "" on failure.
+func encodeJSONToken(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeJSONToken decodes a base64 JSON token into v; an empty token leaves v untouched.
+func decodeJSONToken(token string, v interface{}) error {
+	if token == "" {
+		return nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("invalid base64 token: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("invalid token structure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// TokenRange is a Murmur3 token range (Start, End].
+// The full ring is (math.MinInt64, math.MaxInt64].
+type TokenRange struct {
+	Start int64
+	End   int64
+}
+
+// FullRing returns the whole Murmur3 token ring as a single range.
+func FullRing() TokenRange {
+	return TokenRange{Start: math.MinInt64, End: math.MaxInt64}
+}
+
+// Split divides the range into n contiguous sub-ranges of (almost) equal width.
+func (r TokenRange) Split(n int) []TokenRange {
+	if n <= 1 || r.End <= r.Start {
+		return []TokenRange{r}
+	}
+
+	start := big.NewInt(r.Start)
+	width := new(big.Int).Sub(big.NewInt(r.End), start)
+
+	ranges := make([]TokenRange, 0, n)
+	prev := r.Start
+	for i := 1; i <= n; i++ {
+		end := r.End
+		if i < n {
+			step := new(big.Int).Mul(width, big.NewInt(int64(i)))
+			step.Quo(step, big.NewInt(int64(n)))
+			end = new(big.Int).Add(start, step).Int64()
+		}
+		if end > prev {
+			ranges = append(ranges, TokenRange{Start: prev, End: end})
+			prev = end
+		}
+	}
+	return ranges
+}
+
+// Contains reports whether the token falls inside the range.
+func (r TokenRange) Contains(token int64) bool {
+	return token > r.Start && token <= r.End
+}
+
+// String formats the range as "(start, end]".
+func (r TokenRange) String() string {
+	return fmt.Sprintf("(%d, %d]", r.Start, r.End)
+}
+
+// relation returns the WHERE relation restricting the partition key to the range.
+func (r TokenRange) relation(partitionKeys []string) (string, []interface{}) {
+	fn := "token(" + strings.Join(partitionKeys, ", ") + ")"
+	return fn + " > ? AND " + fn + " <= ?", []interface{}{r.Start, r.End}
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UnionMode selects how a UnionPaginator combines its sources.
+type UnionMode int
+
+const (
+	// UnionConcat returns all rows of the first source, then the second, and so on.
+	UnionConcat UnionMode = iota
+	// UnionMerge interleaves the sources by UnionPaginator.Order.
+	UnionMerge
+)
+
+// UnionPaginator presents several paginators — e.g. the same table in several
+// keyspaces, or different tables — as a single paginated view. The token tracks
+// the position and exhaustion of every source.
+type UnionPaginator struct {
+	Sources  []*Paginator
+	Mode     UnionMode
+	Order    MergeOrder // used by UnionMerge
+	PageSize int
+	Opts     Options // Context, Logger and Metrics of the union itself
+}
+
+// NewUnionPaginator combines the sources. Each source keeps its own query,
+// filters and page size; opts.PageSize sets the size of the combined pages.
+func NewUnionPaginator(sources []*Paginator, mode UnionMode, order MergeOrder, opts Options) *UnionPaginator {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	return &UnionPaginator{
+		Sources:  sources,
+		Mode:     mode,
+		Order:    order,
+		PageSize: pageSize,
+		Opts:     opts,
+	}
+}
+
+// Next fetches the first combined page.
+func (u *UnionPaginator) Next() ([]map[string]interface{}, string, error) {
+	return u.NextWithToken("")
+}
+
+// NextContext is Next with a per-call context, used instead of Options.Context.
+func (u *UnionPaginator) NextContext(ctx context.Context) ([]map[string]interface{}, string, error) {
+	return u.NextWithTokenContext(ctx, "")
+}
+
+// NextWithToken fetches the combined page at the given composite token.
+func (u *UnionPaginator) NextWithToken(token string) ([]map[string]interface{}, string, error) {
+	return u.NextWithTokenContext(u.Opts.context(), token)
+}
+
+// NextWithTokenContext is NextWithToken with a per-call context, used instead of Options.Context.
+func (u *UnionPaginator) NextWithTokenContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	ct, err := DecodeCompositeToken(token)
+	if err != nil {
+		u.Opts.observeInvalidToken(token, err)
+		return nil, "", ErrInvalidToken
+	}
+
+	sources, err := newMergeSources(u.Sources, ct.Parts)
+	if err != nil {
+		u.Opts.observeInvalidToken(token, err)
+		return nil, "", err
+	}
+
+	start := time.Now()
+	var results []map[string]interface{}
+	if u.Mode == UnionMerge {
+		results, err = mergePage(ctx, sources, u.Order, u.PageSize)
+	} else {
+		results, err = concatPage(ctx, sources, u.PageSize)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	u.Opts.log("union_fetched", map[string]interface{}{
+		"sources":      len(u.Sources),
+		"rows_fetched": len(results),
+		"duration_ms":  time.Since(start).Milliseconds(),
+	})
+
+	cursors := mergeCursors(sources)
+	if cursors == nil {
+		return results, "", nil
+	}
+	return results, EncodeCompositeToken(CompositeToken{Parts: cursors, Prev: token}), nil
+}
+
+// Previous fetches the page before the one the token leads to, using the embedded previous token.
+func (u *UnionPaginator) Previous(token string) ([]map[string]interface{}, string, error) {
+	return u.PreviousContext(u.Opts.context(), token)
+}
+
+// PreviousContext is Previous with a per-call context, used instead of Options.Context.
+func (u *UnionPaginator) PreviousContext(ctx context.Context, token string) ([]map[string]interface{}, string, error) {
+	ct, err := DecodeCompositeToken(token)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if ct.Prev == "" {
+		return nil, "", ErrNoPrevToken
+	}
+	return u.NextWithTokenContext(ctx, ct.Prev)
+}
+
+// concatPage drains the sources in order until pageSize rows are collected.
+func concatPage(ctx context.Context, sources []*mergeSource, pageSize int) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, 0, pageSize)
+
+	for _, s := range sources {
+		for len(results) < pageSize {
+			if err := s.load(ctx); err != nil {
+				return nil, err
+			}
+			if len(s.buffer) == 0 {
+				break // source exhausted, move on
+			}
+			results = append(results, s.pop())
+		}
+		if len(results) == pageSize {
+			break
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// shardedSession holds ks1.events with even timestamps and ks2.events with odd ones.
+func shardedSession() *caspagetest.Session {
+	s := caspagetest.NewSession()
+	for first, table := range []string{"ks1.events", "ks2.events"} {
+		var rows []map[string]interface{}
+		for ts := first; ts < 10; ts += 2 {
+			rows = append(rows, map[string]interface{}{"ts": ts})
+		}
+		s.AddTable(table, rows)
+	}
+	return s
+}
+
+func walkUnion(t *testing.T, u *core.UnionPaginator) [][]int {
+	t.Helper()
+	var pages [][]int
+	token := ""
+	for i := 0; i < 10; i++ {
+		rows, next, err := u.NextWithToken(token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pages = append(pages, timestamps(rows))
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	return pages
+}
+
+func TestUnionPaginator(t *testing.T) {
+	session := shardedSession()
+	sources := []*core.Paginator{
+		core.NewPaginator(session, "SELECT * FROM ks1.events", core.Options{PageSize: 3}),
+		core.NewPaginator(session, "SELECT * FROM ks2.events", core.Options{PageSize: 3}),
+	}
+
+	concat := core.NewUnionPaginator(sources, core.UnionConcat, core.MergeOrder{}, core.Options{PageSize: 4})
+	if got := walkUnion(t, concat); !reflect.DeepEqual(got, [][]int{{0, 2, 4, 6}, {8, 1, 3, 5}, {7, 9}}) {
+		t.Errorf("unexpected concatenated pages: %v", got)
+	}
+
+	merge := core.NewUnionPaginator(sources, core.UnionMerge, core.MergeOrder{Column: "ts"}, core.Options{PageSize: 4})
+	if got := walkUnion(t, merge); !reflect.DeepEqual(got, [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}}) {
+		t.Errorf("unexpected merged pages: %v", got)
+	}
+}
+
+func TestUnionPaginator_PerCallContext(t *testing.T) {
+	session := shardedSession()
+	sources := []*core.Paginator{
+		core.NewPaginator(session, "SELECT * FROM ks1.events", core.Options{PageSize: 3}),
+		core.NewPaginator(session, "SELECT * FROM ks2.events", core.Options{PageSize: 3}),
+	}
+	u := core.NewUnionPaginator(sources, core.UnionMerge, core.MergeOrder{Column: "ts"}, core.Options{PageSize: 4})
+
+	rows, token, err := u.NextContext(context.Background())
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d, err %v", len(rows), err)
+	}
+	_, next, err := u.NextWithTokenContext(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := u.NextWithTokenContext(ctx, token); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from NextWithTokenContext, got %v", err)
+	}
+	if _, _, err := u.PreviousContext(ctx, next); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from PreviousContext, got %v", err)
+	}
+
+	// Options.Context is not consulted by the per-call variants
+	u.Opts.Context = ctx
+	if rows, _, err := u.PreviousContext(context.Background(), next); err != nil || !reflect.DeepEqual(timestamps(rows), []int{4, 5, 6, 7}) {
+		t.Errorf("expected the second page, got %v (%v)", timestamps(rows), err)
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// TypedValue is a JSON-friendly value that remembers its Go type, so tokens can
+// round-trip clustering values such as timestamps and UUIDs and bind them again.
+// Types without a dedicated encoding fall back to plain JSON.
+type TypedValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v,omitempty"`
+}
+
+// NewTypedValue encodes v together with its type.
+func NewTypedValue(v interface{}) (TypedValue, error) {
+	var typ string
+	var payload interface{} = v
+
+	switch x := v.(type) {
+	case nil:
+		return TypedValue{Type: "null"}, nil
+	case string:
+		typ = "string"
+	case bool:
+		typ = "bool"
+	case int:
+		typ = "int"
+	case int8:
+		typ = "int8"
+	case int16:
+		typ = "int16"
+	case int32:
+		typ = "int32"
+	case int64:
+		typ = "int64"
+	case float32:
+		typ = "float32"
+	case float64:
+		typ = "float64"
+	case []byte:
+		typ = "bytes" // encoding/json base64-encodes byte slices
+	case time.Time:
+		typ, payload = "time", x.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		typ, payload = "duration", int64(x)
+	case gocql.UUID:
+		typ, payload = "uuid", x.String()
+	case *big.Int:
+		typ, payload = "bigint", x.String()
+	default:
+		typ = "json"
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return TypedValue{}, fmt.Errorf("encode %T: %w", v, err)
+	}
+	return TypedValue{Type: typ, Value: b}, nil
+}
+
+// Decode returns the original Go value.
+func (tv TypedValue) Decode() (interface{}, error) {
+	var err error
+	switch tv.Type {
+	case "null":
+		return nil, nil
+	case "string":
+		var v string
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "bool":
+		var v bool
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "int":
+		var v int
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "int8":
+		var v int8
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "int16":
+		var v int16
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "int32":
+		var v int32
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "int64", "duration":
+		var v int64
+		if err = json.Unmarshal(tv.Value, &v); err != nil {
+			return nil, err
+		}
+		if tv.Type == "duration" {
+			return time.Duration(v), nil
+		}
+		return v, nil
+	case "float32":
+		var v float32
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "float64":
+		var v float64
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "bytes":
+		var v []byte
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	case "time", "uuid", "bigint":
+		var s string
+		if err = json.Unmarshal(tv.Value, &s); err != nil {
+			return nil, err
+		}
+		switch tv.Type {
+		case "time":
+			return time.Parse(time.RFC3339Nano, s)
+		case "uuid":
+			return gocql.ParseUUID(s)
+		default:
+			n, ok := new(big.Int).SetString(s, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid bigint %q", s)
+			}
+			return n, nil
+		}
+	case "json":
+		var v interface{}
+		err = json.Unmarshal(tv.Value, &v)
+		return v, err
+	}
+	return nil, fmt.Errorf("unknown value type %q", tv.Type)
+}
@@ -33,13 +33,22 @@ func main() {
 	// Initialize Prometheus collector
 	collector := metrics.NewPrometheusCollector()
 
+	// One definition serves every request; it is safe for concurrent use
+	users := core.NewDefinition(&core.RealSession{Session: session}, "SELECT * FROM users", core.Options{
+		PageSize: 20,
+		Columns:  []string{"user_id", "app_data", "role_ids", "name", "count"},
+		Metrics:  collector,
+		Logger: func(event string, data map[string]interface{}) {
+			log.Printf("[LOG] %s: %+v\n", event, data)
+		},
+	})
+
 	// ------------------------------
 	// /users endpoint with stateless pagination, filters, logging, and metrics
 	// ------------------------------
 	r.GET("/users", func(c *gin.Context) {
 		pageToken := c.Query("pageToken")
-		pageSizeStr := c.DefaultQuery("pageSize", "20")
-		pageSize, _ := strconv.Atoi(pageSizeStr)
+		pageSize, _ := strconv.Atoi(c.Query("pageSize")) // 0 keeps the definition's 20
 
 		// Example filter parsing from query params:
 		// ?filters=age>25,regionIN(US|CA),active=true
@@ -47,29 +56,20 @@ func main() {
 		filters := parseFilters(filterStr)
 
 		// Use context to apply 5s timeout per request
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		p := core.NewPaginator(&core.RealSession{Session: session}, "SELECT * FROM users", core.Options{
-			PageSize: pageSize,
-			Context:  ctx,
-			Filters:  filters,
-			Columns:  []string{"user_id", "app_data", "role_ids", "name", "count"},
-			Metrics:  collector,
-			Logger: func(event string, data map[string]interface{}) {
-				log.Printf("[LOG] %s: %+v\n", event, data)
-			},
-		})
-
-		results, nextToken, err := p.NextWithToken(pageToken)
+		page, err := users.FetchPage(ctx, pageToken, core.CallOptions{PageSize: pageSize, Filters: filters})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"data":       results,
-			"next_token": nextToken,
+			"data":       page.Rows,
+			"next_token": page.NextToken,
+			"prev_token": page.PrevToken,
+			"has_more":   page.HasMore,
 			"filters":    filters,
 		})
 	})
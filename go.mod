@@ -3,6 +3,7 @@ module github.com/AnukritiSharma1609/caspage
 go 1.23.10
 
 require (
+	github.com/apache/cassandra-gocql-driver/v2 v2.1.2
 	github.com/gin-gonic/gin v1.11.0
 	github.com/gocql/gocql v1.7.0
 	github.com/mitchellh/mapstructure v1.5.0
@@ -0,0 +1,115 @@
+package gocqlv2
+
+import (
+	"errors"
+	"reflect"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	gocqlv1 "github.com/gocql/gocql"
+)
+
+var (
+	coreUUID   = reflect.TypeOf(gocqlv1.UUID{})
+	driverUUID = reflect.TypeOf(gocql.UUID{})
+)
+
+// toDriverValues converts core's UUIDs, alone or in lists, sets and maps, to
+// the v2 type.
+func toDriverValues(values []interface{}) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = convertUUIDs(v, coreUUID, driverUUID)
+	}
+	return out
+}
+
+// fromDriverValue converts v2 UUIDs, alone or in lists, sets and maps (keys
+// and values), to core's type.
+func fromDriverValue(v interface{}) interface{} {
+	return convertUUIDs(v, driverUUID, coreUUID)
+}
+
+// convertUUIDs returns v with every from UUID, at any collection depth,
+// converted to the to UUID type. Values without one are returned as is.
+func convertUUIDs(v interface{}, from, to reflect.Type) interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return v
+	}
+	t := swapType(rv.Type(), from, to)
+	if t == rv.Type() {
+		return v
+	}
+	return convertValue(rv, t).Interface()
+}
+
+// swapType returns t with from replaced by to in its slice and map types.
+func swapType(t, from, to reflect.Type) reflect.Type {
+	switch {
+	case t == from:
+		return to
+	case t.Kind() == reflect.Slice:
+		if elem := swapType(t.Elem(), from, to); elem != t.Elem() {
+			return reflect.SliceOf(elem)
+		}
+	case t.Kind() == reflect.Map:
+		key, elem := swapType(t.Key(), from, to), swapType(t.Elem(), from, to)
+		if key != t.Key() || elem != t.Elem() {
+			return reflect.MapOf(key, elem)
+		}
+	}
+	return t
+}
+
+// convertValue converts v to t, a type built by swapType.
+func convertValue(v reflect.Value, t reflect.Type) reflect.Value {
+	if v.Type() == t {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(convertValue(v.Index(i), t.Elem()))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		for entries := v.MapRange(); entries.Next(); {
+			out.SetMapIndex(convertValue(entries.Key(), t.Key()), convertValue(entries.Value(), t.Elem()))
+		}
+		return out
+	}
+	return v.Convert(t) // both UUID types are [16]byte
+}
+
+// driverErrors pairs the v2 sentinels with the ones core classifies.
+var driverErrors = []struct{ v2, v1 error }{
+	{gocql.ErrTimeoutNoResponse, gocqlv1.ErrTimeoutNoResponse},
+	{gocql.ErrConnectionClosed, gocqlv1.ErrConnectionClosed},
+	{gocql.ErrNoConnections, gocqlv1.ErrNoConnections},
+}
+
+// translatedError matches both the driver error and its v1 equivalent.
+type translatedError struct {
+	err error
+	v1  error
+}
+
+func (e *translatedError) Error() string   { return e.err.Error() }
+func (e *translatedError) Unwrap() []error { return []error{e.err, e.v1} }
+
+func translateError(err error) error {
+	for _, pair := range driverErrors {
+		if errors.Is(err, pair.v2) {
+			return &translatedError{err: err, v1: pair.v1}
+		}
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+package gocqlv2
+
+import (
+	"context"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// diagnostics reports the v2 driver's observations of one query to a
+// core.DiagnosticsCollector, passing them on to the session's observer.
+type diagnostics struct {
+	*core.DiagnosticsCollector
+	next gocql.QueryObserver // observer the driver would have called
+}
+
+func (d *diagnostics) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	coordinator := ""
+	if q.Host != nil {
+		coordinator = q.Host.ConnectAddressAndPort()
+	}
+	d.Request(coordinator)
+	if d.next != nil {
+		d.next.ObserveQuery(ctx, q)
+	}
+}
@@ -0,0 +1,139 @@
+// Package gocqlv2 adapts the Apache Cassandra Go driver
+// (github.com/apache/cassandra-gocql-driver/v2) to core.CassandraSession, so
+// paginators run unchanged on either driver:
+//
+//	session, err := cluster.CreateSession() // *gocql.Session from the v2 driver
+//	p := core.NewPaginator(&gocqlv2.Session{Session: session}, "SELECT * FROM users", opts)
+//
+// Rows and bound values are translated at the boundary: v2 UUIDs are returned as
+// github.com/gocql/gocql UUIDs, the type core uses for tokens and comparisons,
+// and driver errors keep matching the sentinels core classifies.
+package gocqlv2
+
+import (
+	"context"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	gocqlv1 "github.com/gocql/gocql"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// Session wraps a v2 *gocql.Session to implement core.CassandraSession.
+type Session struct {
+	*gocql.Session
+
+	// QueryObserver is the ClusterConfig.QueryObserver of the session, if
+	// any, as for core.RealSession.
+	QueryObserver gocql.QueryObserver
+}
+
+func (s *Session) Query(stmt string, values ...interface{}) core.CassandraQuery {
+	return &Query{Query: s.Session.Query(stmt, toDriverValues(values)...), ctx: context.Background(), observer: s.QueryObserver}
+}
+
+// Query wraps a v2 *gocql.Query.
+type Query struct {
+	*gocql.Query
+	ctx         context.Context
+	observer    gocql.QueryObserver // session observer chained by Diagnose
+	diagnostics *core.DiagnosticsCollector
+}
+
+func (q *Query) PageSize(n int) core.CassandraQuery {
+	q.Query = q.Query.PageSize(n)
+	return q
+}
+
+func (q *Query) PageState(b []byte) core.CassandraQuery {
+	q.Query = q.Query.PageState(b)
+	return q
+}
+
+// WithContext sets the context passed to IterContext.
+func (q *Query) WithContext(ctx context.Context) core.CassandraQuery {
+	q.ctx = ctx
+	return q
+}
+
+// Configure applies the consistency levels, idempotency, driver retries and
+// timestamp setting of cfg.
+func (q *Query) Configure(cfg core.QueryConfig) core.CassandraQuery {
+	q.driver().Configure(cfg)
+	return q
+}
+
+// Diagnose observes the driver requests of the query, passing them on to the
+// session's QueryObserver, traces them when trace is set and fills in d when
+// the iterator is closed.
+func (q *Query) Diagnose(d *core.PageDiagnostics, trace bool) core.CassandraQuery {
+	q.diagnostics = q.driver().Diagnose(d, trace)
+	return q
+}
+
+// driver returns the setters of the wrapped query.
+func (q *Query) driver() core.DriverQuery {
+	return core.DriverQuery{
+		Consistency:       func(level gocqlv1.Consistency) { q.Query = q.Query.Consistency(gocql.Consistency(level)) },
+		SerialConsistency: func(level gocqlv1.SerialConsistency) { q.Query = q.Query.SerialConsistency(gocql.Consistency(level)) },
+		Idempotent:        func() { q.Query = q.Query.Idempotent(true) },
+		Retries:           func(policy core.DriverRetry) { q.Query = q.Query.RetryPolicy(driverRetry(policy)) },
+		DefaultTimestamp:  func(enabled bool) { q.Query = q.Query.DefaultTimestamp(enabled) },
+		Observe: func(c *core.DiagnosticsCollector) {
+			q.Query = q.Query.Observer(&diagnostics{DiagnosticsCollector: c, next: q.observer})
+		},
+		Trace: func(c *core.DiagnosticsCollector) { q.Query = q.Query.Trace(c) },
+	}
+}
+
+// driverRetry is core.DriverRetry as a v2 driver retry policy.
+type driverRetry core.DriverRetry
+
+func (n driverRetry) Attempt(q gocql.RetryableQuery) bool {
+	return core.DriverRetry(n).Retry(q.Attempts())
+}
+
+func (n driverRetry) GetRetryType(err error) gocql.RetryType {
+	if core.DriverRetry(n).SameHost(err) {
+		return gocql.Retry
+	}
+	return gocql.RetryNextHost
+}
+
+func (q *Query) Iter() core.CassandraIter {
+	return &Iter{Iter: q.Query.IterContext(q.ctx), driver: core.DriverIter{Diagnostics: q.diagnostics}}
+}
+
+// Iter wraps a v2 *gocql.Iter.
+type Iter struct {
+	*gocql.Iter
+	driver core.DriverIter
+}
+
+// MapScan reads the next row, converting driver UUIDs to core's UUID type.
+func (i *Iter) MapScan(m map[string]interface{}) bool {
+	if !i.driver.MapScan(i.Iter, m) {
+		return false
+	}
+	for k, v := range m {
+		m[k] = fromDriverValue(v)
+	}
+	return true
+}
+
+func (i *Iter) PageState() []byte { return i.Iter.PageState() }
+
+// Warnings returns the server warnings of the driver pages read so far.
+func (i *Iter) Warnings() []string { return i.driver.Warnings(i.Iter) }
+
+// Close returns the driver error, translated so core can classify it.
+func (i *Iter) Close() error {
+	return translateError(i.driver.Close(i.Iter))
+}
+
+var (
+	_ core.CassandraSession  = (*Session)(nil)
+	_ core.ConfigurableQuery = (*Query)(nil)
+	_ core.DiagnosticQuery   = (*Query)(nil)
+	_ core.WarningIter       = (*Iter)(nil)
+)
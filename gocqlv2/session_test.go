@@ -0,0 +1,80 @@
+package gocqlv2_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/AnukritiSharma1609/caspage/gocqlv2"
+	"github.com/AnukritiSharma1609/caspage/internal/conformance"
+)
+
+func TestSession_Conformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T, addr string) core.CassandraSession {
+		cluster := gocql.NewCluster(addr)
+		cluster.ProtoVersion = 4
+		cluster.Timeout = 5 * time.Second
+		session, err := cluster.CreateSession()
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		t.Cleanup(session.Close)
+		return &gocqlv2.Session{Session: session}
+	})
+}
+
+// countingObserver counts the queries the driver reports.
+type countingObserver struct{ queries atomic.Int32 }
+
+func (o *countingObserver) ObserveQuery(context.Context, gocql.ObservedQuery) { o.queries.Add(1) }
+
+func TestSession_KeepsQueryObserver(t *testing.T) {
+	conformance.RunQueryObserver(t, func(t *testing.T, addr string) (core.CassandraSession, func() int) {
+		observer := &countingObserver{}
+		cluster := gocql.NewCluster(addr)
+		cluster.ProtoVersion = 4
+		cluster.Timeout = 5 * time.Second
+		cluster.QueryObserver = observer
+		session, err := cluster.CreateSession()
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		t.Cleanup(session.Close)
+		return &gocqlv2.Session{Session: session, QueryObserver: observer}, func() int { return int(observer.queries.Load()) }
+	})
+}
+
+func TestSession_DriverRetries(t *testing.T) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", []map[string]interface{}{{"id": int64(1)}, {"id": int64(2)}})
+	s.InjectError("FROM users", caspagetest.ErrReadTimeout, 2)
+	srv, err := caspagetest.NewServer(s)
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	defer srv.Close()
+
+	cluster := gocql.NewCluster(srv.Addr())
+	cluster.ProtoVersion = 4
+	cluster.Timeout = 5 * time.Second
+	session, err := cluster.CreateSession()
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	// The v2 driver retries on its own, without core.RetryPolicy
+	p := core.NewPaginator(&gocqlv2.Session{Session: session}, "SELECT * FROM users", core.Options{
+		PageSize:    5,
+		QueryConfig: &core.QueryConfig{Idempotent: true, DriverRetries: 2},
+	})
+	rows, _, err := p.Next()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("expected the driver to retry, got %d rows (%v)", len(rows), err)
+	}
+}
@@ -0,0 +1,418 @@
+// Package conformance is a driver-independent test suite for CassandraSession
+// adapters. Every case serves in-memory tables through a caspagetest.Server and
+// pages through them with the adapter under test, so all adapters are held to
+// the same paging, value, error and context behaviour.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/gocql/gocql"
+)
+
+// Connect opens a session to the server at addr through the adapter under
+// test, registering its cleanup with t.
+type Connect func(t *testing.T, addr string) core.CassandraSession
+
+// Run runs the suite.
+func Run(t *testing.T, connect Connect) {
+	cases := []struct {
+		name string
+		run  func(*testing.T, Connect)
+	}{
+		{"Paging", testPaging},
+		{"Filters", testFilters},
+		{"MidPageResume", testMidPageResume},
+		{"Keyset", testKeyset},
+		{"Collections", testCollections},
+		{"Count", testCount},
+		{"Errors", testErrors},
+		{"Context", testContext},
+		{"Diagnostics", testDiagnostics},
+		{"LookAhead", testLookAhead},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) { c.run(t, connect) })
+	}
+}
+
+// ConnectObserved is Connect for a cluster with a ClusterConfig.QueryObserver,
+// which the adapter is given too. observed returns the number of queries the
+// observer has seen.
+type ConnectObserved func(t *testing.T, addr string) (db core.CassandraSession, observed func() int)
+
+// RunQueryObserver checks that the adapter keeps calling the cluster's
+// QueryObserver, whether or not a page is diagnosed.
+func RunQueryObserver(t *testing.T, connect ConnectObserved) {
+	s := caspagetest.NewSession()
+	s.AddTable("users", []map[string]interface{}{{"id": int64(1)}, {"id": int64(2)}})
+	srv, err := caspagetest.NewServer(s)
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	db, observed := connect(t, srv.Addr())
+
+	// Unsampled pages leave the driver's observer alone, sampled ones chain to it
+	for _, diagnostics := range []*core.Diagnostics{nil, {}} {
+		before := observed()
+		p := core.NewPaginator(db, "SELECT * FROM users", core.Options{PageSize: 5, Diagnostics: diagnostics})
+		_, info, err := p.NextWithTokenInfo(context.Background(), "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if observed() == before {
+			t.Errorf("expected the cluster's observer to see the query (diagnostics %v)", diagnostics != nil)
+		}
+		if (info.Diagnostics != nil) != (diagnostics != nil) {
+			t.Errorf("unexpected diagnostics %+v", info.Diagnostics)
+		}
+	}
+}
+
+// serve starts a server for a fresh session holding 23 events and connects to it.
+func serve(t *testing.T, connect Connect) (*caspagetest.Session, core.CassandraSession) {
+	t.Helper()
+	s := caspagetest.NewSession()
+	s.AddTable("events", events(23))
+
+	srv, err := caspagetest.NewServer(s)
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return s, connect(t, srv.Addr())
+}
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// events builds one partition ordered by ts, with typed clustering values.
+func events(n int) []map[string]interface{} {
+	regions := []string{"US", "CA", "EU"}
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{
+			"device":  "d1",
+			"ts":      int64(i),
+			"at":      base.Add(time.Duration(i) * time.Minute),
+			"id":      gocql.UUIDFromTime(base.Add(time.Duration(i) * time.Second)),
+			"region":  regions[i%len(regions)],
+			"payload": make([]byte, 64),
+		}
+	}
+	return rows
+}
+
+func ids(rows []map[string]interface{}) []int64 {
+	out := make([]int64, len(rows))
+	for i, row := range rows {
+		out[i], _ = row["ts"].(int64)
+	}
+	return out
+}
+
+// all reads every page from token and returns the ids and page count.
+func all(t *testing.T, p *core.Paginator, token string) ([]int64, int) {
+	t.Helper()
+	it := p.Pages(token)
+	defer it.Close()
+	var got []int64
+	pages := 0
+	for it.Next() {
+		got = append(got, ids(it.Rows())...)
+		pages++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return got, pages
+}
+
+func assertRange(t *testing.T, got []int64, from, to int64) {
+	t.Helper()
+	if len(got) != int(to-from) {
+		t.Fatalf("expected ids %d..%d, got %v", from, to-1, got)
+	}
+	for i, id := range got {
+		if id != from+int64(i) {
+			t.Fatalf("expected ids %d..%d, got %v", from, to-1, got)
+		}
+	}
+}
+
+func testPaging(t *testing.T, connect Connect) {
+	_, db := serve(t, connect)
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{PageSize: 5})
+
+	got, pages := all(t, p, "")
+	assertRange(t, got, 0, 23)
+	if pages != 5 {
+		t.Errorf("expected 5 pages, got %d", pages)
+	}
+
+	// Tokens resume across calls, and Previous returns the page a token came with
+	_, token, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, next, err := p.NextWithToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRange(t, ids(rows), 5, 10)
+	if _, _, err := p.NextWithToken(next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, _, err = p.Previous(next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRange(t, ids(rows), 5, 10)
+}
+
+func testFilters(t *testing.T, connect Connect) {
+	_, db := serve(t, connect)
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{
+		PageSize: 3,
+		Columns:  []string{"ts", "region", "at"},
+		Filters: map[string]interface{}{
+			"device":    "d1",
+			"region IN": []string{"US", "CA"},
+			"ts >=":     6,
+			"at <":      base.Add(15 * time.Minute),
+		},
+	})
+
+	got, _ := all(t, p, "")
+	want := []int64{6, 7, 9, 10, 12, 13}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	rows, _, err := p.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if at, ok := rows[0]["at"].(time.Time); !ok || !at.Equal(base.Add(6*time.Minute)) || len(rows[0]) != 3 {
+		t.Errorf("unexpected row %#v", rows[0])
+	}
+}
+
+func testMidPageResume(t *testing.T, connect Connect) {
+	_, db := serve(t, connect)
+	// Pages end after about three rows, inside the driver pages
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{PageSize: 10, MaxPageBytes: 250})
+
+	got, pages := all(t, p, "")
+	assertRange(t, got, 0, 23)
+	if pages < 5 {
+		t.Errorf("expected pages cut by MaxPageBytes, got %d pages", pages)
+	}
+}
+
+func testKeyset(t *testing.T, connect Connect) {
+	_, db := serve(t, connect)
+	for _, keys := range [][]string{{"at"}, {"id"}} {
+		p := core.NewPaginator(db, "SELECT * FROM events", core.Options{
+			PageSize: 5,
+			Keyset:   true,
+			Keys:     &core.TableKeys{PartitionKeys: []string{"device"}, ClusteringKeys: keys},
+			Filters:  map[string]interface{}{"device": "d1"},
+		})
+
+		rows, token, err := p.Last()
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", keys, err)
+		}
+		assertRange(t, ids(rows), 18, 23)
+
+		rows, token, err = p.Previous(token)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", keys, err)
+		}
+		assertRange(t, ids(rows), 13, 18)
+
+		rows, _, err = p.NextWithToken(token)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", keys, err)
+		}
+		assertRange(t, ids(rows), 18, 23)
+		if _, ok := rows[0]["id"].(gocql.UUID); !ok {
+			t.Errorf("expected a gocql.UUID, got %T", rows[0]["id"])
+		}
+	}
+}
+
+func testCollections(t *testing.T, connect Connect) {
+	s, db := serve(t, connect)
+	owner, editor := gocql.UUIDFromTime(base), gocql.UUIDFromTime(base.Add(time.Second))
+	s.AddTable("docs", []map[string]interface{}{{
+		"doc":     "d1",
+		"roles":   map[gocql.UUID]string{owner: "owner", editor: "editor"},
+		"ranks":   map[string]gocql.UUID{"first": owner},
+		"readers": []gocql.UUID{owner, editor},
+	}})
+
+	// UUIDs come back as gocql.UUID in lists and sets, map keys and map values
+	rows, _, err := core.NewPaginator(db, "SELECT * FROM docs", core.Options{PageSize: 5}).Next()
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d (%v)", len(rows), err)
+	}
+	row := rows[0]
+	if roles, ok := row["roles"].(map[gocql.UUID]string); !ok || roles[owner] != "owner" || roles[editor] != "editor" {
+		t.Errorf("unexpected roles %T %v", row["roles"], row["roles"])
+	}
+	if ranks, ok := row["ranks"].(map[string]gocql.UUID); !ok || ranks["first"] != owner {
+		t.Errorf("unexpected ranks %T %v", row["ranks"], row["ranks"])
+	}
+	if readers, ok := row["readers"].([]gocql.UUID); !ok || len(readers) != 2 || readers[1] != editor {
+		t.Errorf("unexpected readers %T %v", row["readers"], row["readers"])
+	}
+}
+
+func testCount(t *testing.T, connect Connect) {
+	_, db := serve(t, connect)
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{
+		PageSize: 5,
+		Filters:  map[string]interface{}{"region": "EU"},
+	})
+
+	count, err := p.Count(core.CountOptions{Mode: core.CountExact})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count.Count != 7 {
+		t.Errorf("expected 7 rows, got %d", count.Count)
+	}
+}
+
+func testErrors(t *testing.T, connect Connect) {
+	s, db := serve(t, connect)
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{PageSize: 5})
+
+	kinds := []struct {
+		err  error
+		kind core.ErrorKind
+	}{
+		{caspagetest.ErrReadTimeout, core.KindTimeout},
+		{caspagetest.ErrUnavailable, core.KindUnavailable},
+		{caspagetest.ErrUnauthorized, core.KindUnauthorized},
+	}
+	for _, k := range kinds {
+		// Every attempt fails, whatever the driver's own retry policy
+		s.InjectError("FROM events", k.err, -1)
+		_, _, err := p.Next()
+		s.ClearErrors()
+
+		var qe *core.QueryError
+		if !errors.As(err, &qe) || qe.Kind != k.kind {
+			t.Errorf("expected a %v QueryError, got %v", k.kind, err)
+		}
+	}
+
+	missing := core.NewPaginator(db, "SELECT * FROM missing", core.Options{PageSize: 5})
+	var qe *core.QueryError
+	if _, _, err := missing.Next(); !errors.As(err, &qe) || qe.Kind != core.KindInvalidQuery {
+		t.Errorf("expected an invalid query error, got %v", err)
+	}
+
+	if rows, _, err := p.Next(); err != nil || len(rows) != 5 {
+		t.Errorf("expected recovery, got %d rows, err %v", len(rows), err)
+	}
+}
+
+func testContext(t *testing.T, connect Connect) {
+	s, db := serve(t, connect)
+	s.SetLatency(300 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{PageSize: 5})
+
+	start := time.Now()
+	_, _, err := p.NextContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("the context was not passed to the driver: %v", elapsed)
+	}
+}
+
+func testDiagnostics(t *testing.T, connect Connect) {
+	s, db := serve(t, connect)
+	s.InjectWarning("FROM events", "Read 5 live rows and 1200 tombstone cells")
+
+	var logged bool
+	p := core.NewPaginator(db, "SELECT * FROM events", core.Options{
+		PageSize:    5,
+		Diagnostics: &core.Diagnostics{},
+		Logger: func(event string, data map[string]interface{}) {
+			logged = logged || event == "page_diagnostics"
+		},
+	})
+
+	rows, info, err := p.NextWithTokenInfo(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRange(t, ids(rows), 0, 5)
+
+	d := info.Diagnostics
+	if d == nil {
+		t.Fatal("expected diagnostics for the page")
+	}
+	if d.Coordinator == "" || d.Requests < 1 || d.Attempts != 1 || d.Latency <= 0 {
+		t.Errorf("unexpected diagnostics %+v", d)
+	}
+	if len(d.TombstoneWarnings()) != 1 {
+		t.Errorf("expected the tombstone warning, got %v", d.Warnings)
+	}
+	if !logged {
+		t.Error("expected a page_diagnostics log event")
+	}
+
+	// Warnings reach PageInfo without diagnostics too
+	p = core.NewPaginator(db, "SELECT * FROM events", core.Options{PageSize: 5})
+	_, info, err = p.NextWithTokenInfo(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Diagnostics != nil || len(info.Warnings) != 1 {
+		t.Errorf("expected only the warnings, got %+v", info)
+	}
+}
+
+func testLookAhead(t *testing.T, connect Connect) {
+	s, db := serve(t, connect)
+	s.AddTable("exact", events(20))
+
+	// The second driver page ends at the last row, yet carries a page state
+	p := core.NewPaginator(db, "SELECT * FROM exact", core.Options{
+		PageSize:  10,
+		Adaptive:  &core.AdaptiveFetch{MinFetchSize: 10, MaxFetchSize: 10},
+		LookAhead: true,
+	})
+	_, token, err := p.Next()
+	if err != nil || token == "" {
+		t.Fatalf("unexpected first page: token %q (%v)", token, err)
+	}
+	rows, token, err := p.NextWithToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRange(t, ids(rows), 10, 20)
+	if token != "" {
+		t.Errorf("expected no next token after the last row, got %q", token)
+	}
+}
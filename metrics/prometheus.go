@@ -12,6 +12,12 @@ type PrometheusCollector struct {
 	pageFetchCount    prometheus.Counter
 	pageFetchDuration prometheus.Histogram
 	pageErrorCount    prometheus.Counter
+	fetchSize         prometheus.Gauge
+	rowBytes          prometheus.Gauge
+	retryCount        prometheus.Counter
+	rateLimitWait     prometheus.Histogram
+	pageWarnings      prometheus.Counter
+	tombstoneWarnings prometheus.Counter
 }
 
 // NewPrometheusCollector creates and registers Prometheus metrics.
@@ -30,12 +36,43 @@ func NewPrometheusCollector() *PrometheusCollector {
 			Name: "caspage_errors_total",
 			Help: "Total number of pagination errors",
 		}),
+		fetchSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "caspage_fetch_size",
+			Help: "Driver fetch size chosen by adaptive paging",
+		}),
+		rowBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "caspage_row_bytes",
+			Help: "Approximate average row size seen by adaptive paging",
+		}),
+		retryCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "caspage_retries_total",
+			Help: "Total number of retried query attempts",
+		}),
+		rateLimitWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "caspage_rate_limit_wait_seconds",
+			Help:    "Time page fetches waited for the rate limiter",
+			Buckets: prometheus.DefBuckets,
+		}),
+		pageWarnings: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "caspage_page_warnings_total",
+			Help: "Total number of server warnings on diagnosed pages",
+		}),
+		tombstoneWarnings: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "caspage_tombstone_warnings_total",
+			Help: "Total number of tombstone warnings on diagnosed pages",
+		}),
 	}
 
 	prometheus.MustRegister(
 		c.pageFetchCount,
 		c.pageFetchDuration,
 		c.pageErrorCount,
+		c.fetchSize,
+		c.rowBytes,
+		c.retryCount,
+		c.rateLimitWait,
+		c.pageWarnings,
+		c.tombstoneWarnings,
 	)
 
 	return c
@@ -50,4 +87,26 @@ func (c *PrometheusCollector) ObserveError(err error) {
 	c.pageErrorCount.Inc()
 }
 
-var _ core.MetricsCollector = (*PrometheusCollector)(nil) // compile-time check
+func (c *PrometheusCollector) ObserveFetchSize(size int, bytesPerRow int) {
+	c.fetchSize.Set(float64(size))
+	c.rowBytes.Set(float64(bytesPerRow))
+}
+
+func (c *PrometheusCollector) ObserveRetry(attempt int, err error) {
+	c.retryCount.Inc()
+}
+
+func (c *PrometheusCollector) ObserveRateLimitWait(wait time.Duration) {
+	c.rateLimitWait.Observe(wait.Seconds())
+}
+
+func (c *PrometheusCollector) ObservePageDiagnostics(d core.PageDiagnostics) {
+	c.pageWarnings.Add(float64(len(d.Warnings)))
+	c.tombstoneWarnings.Add(float64(len(d.TombstoneWarnings())))
+}
+
+var _ core.MetricsCollector = (*PrometheusCollector)(nil)    // compile-time check
+var _ core.FetchSizeObserver = (*PrometheusCollector)(nil)   // compile-time check
+var _ core.RetryObserver = (*PrometheusCollector)(nil)       // compile-time check
+var _ core.RateLimitObserver = (*PrometheusCollector)(nil)   // compile-time check
+var _ core.DiagnosticsObserver = (*PrometheusCollector)(nil) // compile-time check
@@ -0,0 +1,29 @@
+// Package scylla adapts ScyllaDB sessions to core.CassandraSession and reads
+// the shard layout of Scylla nodes, so token-range scans can be split per shard.
+//
+// The scylladb/gocql fork keeps the github.com/gocql/gocql import path and is
+// selected with a replace directive in the application's go.mod:
+//
+//	replace github.com/gocql/gocql => github.com/scylladb/gocql vX.Y.Z
+//
+// Session, which is core.RealSession, then wraps the fork's *gocql.Session,
+// whose shard-aware connection pool sends queries with a routing key straight
+// to the owning shard:
+//
+//	layout, err := scylla.DiscoverShards(ctx, "10.0.0.1:9042", nil)
+//	db := &scylla.Session{Session: session}
+//	for shard, ranges := range layout.ShardRanges(core.FullRing()) {
+//		// one worker per shard, one paginator per range with Options.TokenRange
+//	}
+//
+// Without the replace directive the same code runs on the upstream driver,
+// which is not shard-aware but serves Scylla like any Cassandra cluster.
+package scylla
+
+import "github.com/AnukritiSharma1609/caspage/core"
+
+// Session wraps a *gocql.Session connected to ScyllaDB to implement
+// core.CassandraSession. The fork shares the upstream import path, so the
+// adapter is core.RealSession itself, built against whichever driver the
+// replace directive selects.
+type Session = core.RealSession
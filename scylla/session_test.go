@@ -0,0 +1,59 @@
+package scylla_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/AnukritiSharma1609/caspage/caspagetest"
+	"github.com/AnukritiSharma1609/caspage/core"
+	"github.com/AnukritiSharma1609/caspage/internal/conformance"
+	"github.com/AnukritiSharma1609/caspage/scylla"
+)
+
+func TestSession_Conformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T, addr string) core.CassandraSession {
+		cluster := gocql.NewCluster(addr)
+		cluster.ProtoVersion = 4
+		cluster.Timeout = 5 * time.Second
+		session, err := cluster.CreateSession()
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		t.Cleanup(session.Close)
+		return &scylla.Session{Session: session}
+	})
+}
+
+func TestDiscoverShards(t *testing.T) {
+	srv, err := caspagetest.NewServer(caspagetest.NewSession())
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Apache Cassandra advertises no shards
+	if _, err := scylla.DiscoverShards(ctx, srv.Addr(), nil); !errors.Is(err, scylla.ErrNotScylla) {
+		t.Errorf("expected ErrNotScylla, got %v", err)
+	}
+
+	srv.SetSupported("SCYLLA_NR_SHARDS", "8")
+	srv.SetSupported("SCYLLA_SHARDING_IGNORE_MSB", "12")
+	layout, err := scylla.DiscoverShards(ctx, srv.Addr(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if layout != (core.ShardLayout{Shards: 8, IgnoreMSB: 12}) {
+		t.Errorf("unexpected layout %v", layout)
+	}
+
+	srv.SetSupported("SCYLLA_NR_SHARDS", "many")
+	if _, err := scylla.DiscoverShards(ctx, srv.Addr(), nil); err == nil {
+		t.Error("expected an error for an invalid shard count")
+	}
+}
@@ -0,0 +1,167 @@
+package scylla
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/AnukritiSharma1609/caspage/core"
+)
+
+// ErrNotScylla is returned by DiscoverShards when the node does not advertise
+// a shard layout, as with Apache Cassandra.
+var ErrNotScylla = errors.New("node did not advertise a Scylla shard layout")
+
+// Options advertised by Scylla nodes in the SUPPORTED handshake response.
+const (
+	optionShards    = "SCYLLA_NR_SHARDS"
+	optionIgnoreMSB = "SCYLLA_SHARDING_IGNORE_MSB"
+)
+
+// Native protocol v4 framing used for the OPTIONS request.
+const (
+	protocolVersion = 0x04
+	opError         = 0x00
+	opOptions       = 0x05
+	opSupported     = 0x06
+)
+
+// DiscoverShards reads the shard layout of the node at addr ("host:port") from
+// its native protocol handshake. The OPTIONS request needs no authentication;
+// pass tlsConfig when client encryption is enabled. Nodes of one cluster
+// usually share a layout, but it depends on each node's CPU count.
+func DiscoverShards(ctx context.Context, addr string, tlsConfig *tls.Config) (core.ShardLayout, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return core.ShardLayout{}, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return core.ShardLayout{}, err
+		}
+		conn = tlsConn
+	}
+
+	// OPTIONS: version, flags, stream, opcode and an empty body
+	if _, err := conn.Write([]byte{protocolVersion, 0, 0, 0, opOptions, 0, 0, 0, 0}); err != nil {
+		return core.ShardLayout{}, err
+	}
+
+	r := bufio.NewReader(conn)
+	var header [9]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if ctx.Err() != nil {
+			return core.ShardLayout{}, ctx.Err()
+		}
+		return core.ShardLayout{}, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[5:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return core.ShardLayout{}, err
+	}
+
+	switch header[4] {
+	case opSupported:
+	case opError:
+		return core.ShardLayout{}, fmt.Errorf("options request failed: %s", errorMessage(body))
+	default:
+		return core.ShardLayout{}, fmt.Errorf("unexpected response opcode 0x%02x", header[4])
+	}
+
+	options, err := parseMultimap(body)
+	if err != nil {
+		return core.ShardLayout{}, err
+	}
+	return layoutOf(options)
+}
+
+// layoutOf reads the shard layout from the SUPPORTED options.
+func layoutOf(options map[string][]string) (core.ShardLayout, error) {
+	if len(options[optionShards]) == 0 {
+		return core.ShardLayout{}, ErrNotScylla
+	}
+	shards, err := strconv.Atoi(options[optionShards][0])
+	if err != nil {
+		return core.ShardLayout{}, fmt.Errorf("invalid %s: %w", optionShards, err)
+	}
+
+	layout := core.ShardLayout{Shards: shards}
+	if values := options[optionIgnoreMSB]; len(values) > 0 {
+		if layout.IgnoreMSB, err = strconv.Atoi(values[0]); err != nil {
+			return core.ShardLayout{}, fmt.Errorf("invalid %s: %w", optionIgnoreMSB, err)
+		}
+	}
+	if !layout.Valid() {
+		return core.ShardLayout{}, fmt.Errorf("invalid shard layout: %v", layout)
+	}
+	return layout, nil
+}
+
+// parseMultimap decodes a [string multimap] body.
+func parseMultimap(b []byte) (map[string][]string, error) {
+	errShort := errors.New("malformed SUPPORTED response")
+	readShort := func() (int, bool) {
+		if len(b) < 2 {
+			return 0, false
+		}
+		n := int(binary.BigEndian.Uint16(b))
+		b = b[2:]
+		return n, true
+	}
+	readString := func() (string, bool) {
+		n, ok := readShort()
+		if !ok || len(b) < n {
+			return "", false
+		}
+		s := string(b[:n])
+		b = b[n:]
+		return s, true
+	}
+
+	keys, ok := readShort()
+	if !ok {
+		return nil, errShort
+	}
+	m := make(map[string][]string, keys)
+	for i := 0; i < keys; i++ {
+		key, ok := readString()
+		if !ok {
+			return nil, errShort
+		}
+		n, ok := readShort()
+		if !ok {
+			return nil, errShort
+		}
+		values := make([]string, n)
+		for j := range values {
+			if values[j], ok = readString(); !ok {
+				return nil, errShort
+			}
+		}
+		m[key] = values
+	}
+	return m, nil
+}
+
+// errorMessage extracts the message of an ERROR body: [int code][string message].
+func errorMessage(body []byte) string {
+	if len(body) < 6 {
+		return "unknown error"
+	}
+	n := int(binary.BigEndian.Uint16(body[4:]))
+	if len(body) < 6+n {
+		return "unknown error"
+	}
+	return string(body[6 : 6+n])
+}